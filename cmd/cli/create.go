@@ -1,22 +1,35 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"log"
-	"net/url"
 	"os"
+	"time"
 
 	"github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/database"
 	"github.com/axellelanca/urlshortener/internal/repository"
 	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/axellelanca/urlshortener/internal/urlpolicy"
 	"github.com/spf13/cobra"
-	"gorm.io/driver/sqlite"
-	"gorm.io/gorm"
 )
 
 // longURLFlag stocke la valeur du flag --url
 var longURLFlag string
 
+// customCodeFlag stocke la valeur du flag --code (alias personnalisé optionnel)
+var customCodeFlag string
+
+// notifyFlag stocke la valeur du flag --notify
+var notifyFlag bool
+
+// expiresAtFlag et ttlFlag stockent les valeurs des flags --expires-at et --ttl
+var (
+	expiresAtFlag string
+	ttlFlag       time.Duration
+)
+
 // CreateCmd représente la commande 'create'
 var CreateCmd = &cobra.Command{
 	Use:   "create",
@@ -24,16 +37,38 @@ var CreateCmd = &cobra.Command{
 	Long: `Cette commande raccourcit une URL longue fournie et affiche le code court généré.
 
 Exemple:
-  url-shortener create --url="https://www.google.com/search?q=go+lang"`,
+  url-shortener create --url="https://www.google.com/search?q=go+lang"
+  url-shortener create --url="https://example.com/promo" --code="spring-sale"
+  url-shortener create --url="https://example.com/promo" --ttl=72h
+  url-shortener create --url="https://example.com/promo" --expires-at="2025-12-31T23:59:59Z"
+  url-shortener create --url="https://example.com/promo" --notify`,
 	Run: func(cobraCmd *cobra.Command, args []string) {
 		if longURLFlag == "" {
 			fmt.Println("Erreur: Le flag --url est requis")
 			os.Exit(1)
 		}
 
-		// Validation basique du format de l'URL
-		if _, err := url.ParseRequestURI(longURLFlag); err != nil {
-			fmt.Printf("Erreur: URL invalide: %v\n", err)
+		// Validation de l'alias personnalisé avant toute connexion à la base
+		if customCodeFlag != "" {
+			if err := services.ValidateCustomCode(customCodeFlag); err != nil {
+				fmt.Printf("Erreur: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		// Calcul de la date d'expiration éventuelle
+		var expiresAtInput *time.Time
+		if expiresAtFlag != "" {
+			t, err := time.Parse(time.RFC3339, expiresAtFlag)
+			if err != nil {
+				fmt.Printf("Erreur: date d'expiration invalide (format RFC 3339 attendu): %v\n", err)
+				os.Exit(1)
+			}
+			expiresAtInput = &t
+		}
+		expiresAt, err := services.ResolveExpiration(expiresAtInput, ttlFlag, time.Now())
+		if err != nil {
+			fmt.Printf("Erreur: %v\n", err)
 			os.Exit(1)
 		}
 
@@ -41,25 +76,34 @@ Exemple:
 			log.Fatal("FATAL: La configuration n'est pas initialisée")
 		}
 
-		// Initialiser la connexion à la base de données SQLite
-		db, err := gorm.Open(sqlite.Open(cmd.Cfg.Database.Name), &gorm.Config{})
+		// Initialiser la connexion à la base de données configurée
+		db, err := database.Open(cmd.Cfg)
 		if err != nil {
 			log.Fatalf("FATAL: Échec de la connexion à la base de données: %v", err)
 		}
+		defer database.Close(db)
 
-		sqlDB, err := db.DB()
+		// Charger la politique des URLs de destination
+		urlPolicy, err := urlpolicy.Load(cmd.Cfg)
 		if err != nil {
-			log.Fatalf("FATAL: Échec de l'obtention de la base de données SQL sous-jacente: %v", err)
+			log.Fatalf("FATAL: Configuration url_policy invalide: %v", err)
 		}
-		defer sqlDB.Close()
 
 		// Initialiser les repositories et services
 		linkRepo := repository.NewLinkRepository(db)
-		linkService := services.NewLinkService(linkRepo)
+		linkService := services.NewLinkService(linkRepo, urlPolicy)
 
 		// Créer le lien court
-		link, err := linkService.CreateLink(longURLFlag)
+		link, err := linkService.CreateLink(longURLFlag, services.CreateLinkOptions{
+			CustomCode: customCodeFlag,
+			ExpiresAt:  expiresAt,
+			Notify:     notifyFlag,
+		})
 		if err != nil {
+			if errors.Is(err, services.ErrShortCodeTaken) || errors.Is(err, urlpolicy.ErrRejected) {
+				fmt.Printf("Erreur: %v\n", err)
+				os.Exit(1)
+			}
 			log.Fatalf("FATAL: Erreur lors de la création du lien: %v", err)
 		}
 
@@ -67,11 +111,21 @@ Exemple:
 		fmt.Printf("URL courte créée avec succès:\n")
 		fmt.Printf("Code: %s\n", link.ShortCode)
 		fmt.Printf("URL complète: %s\n", fullShortURL)
+		if link.ExpiresAt != nil {
+			fmt.Printf("Expire le: %s\n", link.ExpiresAt.Format(time.RFC3339))
+		}
+		if link.Notify {
+			fmt.Printf("Notifications: %s\n", formatNotify(link.Notify))
+		}
 	},
 }
 
 func init() {
 	CreateCmd.Flags().StringVar(&longURLFlag, "url", "", "URL longue à raccourcir")
+	CreateCmd.Flags().StringVar(&customCodeFlag, "code", "", "Alias personnalisé pour l'URL courte (optionnel)")
+	CreateCmd.Flags().StringVar(&expiresAtFlag, "expires-at", "", "Date d'expiration du lien au format RFC 3339 (optionnel)")
+	CreateCmd.Flags().DurationVar(&ttlFlag, "ttl", 0, "Durée de vie du lien, ex: 24h (optionnel)")
+	CreateCmd.Flags().BoolVar(&notifyFlag, "notify", false, "Notifier les changements d'état de la destination (optionnel)")
 	CreateCmd.MarkFlagRequired("url")
 	cmd.RootCmd.AddCommand(CreateCmd)
 }
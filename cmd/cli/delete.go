@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+// deleteCodeFlag stocke la valeur du flag --code de la commande 'delete'
+var deleteCodeFlag string
+
+// DeleteCmd représente la commande 'delete'
+var DeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Place un lien court dans la corbeille.",
+	Long: `Cette commande supprime un lien court en le plaçant dans la corbeille.
+L'historique de ses clics est conservé : le lien peut être restauré avec
+'trash restore' tant qu'il n'a pas été purgé avec 'trash purge'.
+
+Exemple:
+  url-shortener delete --code="xyz123"`,
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		if deleteCodeFlag == "" {
+			fmt.Println("Erreur: Le flag --code est requis")
+			os.Exit(1)
+		}
+
+		if cmd.Cfg == nil {
+			log.Fatal("FATAL: La configuration n'est pas initialisée")
+		}
+
+		// Initialiser la connexion à la base de données configurée
+		db, err := database.Open(cmd.Cfg)
+		if err != nil {
+			log.Fatalf("FATAL: Échec de la connexion à la base de données: %v", err)
+		}
+		defer database.Close(db)
+
+		// Initialiser les repositories et services
+		linkRepo := repository.NewLinkRepository(db)
+		linkService := services.NewLinkService(linkRepo, nil)
+
+		if err := linkService.DeleteLink(deleteCodeFlag, nil); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				fmt.Printf("Erreur: Code court '%s' introuvable\n", deleteCodeFlag)
+				os.Exit(1)
+			}
+			log.Fatalf("FATAL: Erreur lors de la suppression du lien: %v", err)
+		}
+
+		fmt.Printf("Lien '%s' déplacé dans la corbeille.\n", deleteCodeFlag)
+	},
+}
+
+func init() {
+	DeleteCmd.Flags().StringVar(&deleteCodeFlag, "code", "", "Code court du lien à supprimer")
+	DeleteCmd.MarkFlagRequired("code")
+	cmd.RootCmd.AddCommand(DeleteCmd)
+}
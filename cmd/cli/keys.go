@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+// Flags des sous-commandes de 'keys'
+var (
+	keyNameFlag string
+	keyIDFlag   uint
+)
+
+// KeysCmd représente la commande 'keys', qui regroupe la gestion des clés d'API.
+var KeysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Gère les clés d'API donnant accès aux routes /api/v1.",
+}
+
+// KeysCreateCmd représente la commande 'keys create'
+var KeysCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Crée une nouvelle clé d'API.",
+	Long: `Cette commande génère une nouvelle clé d'API. Le secret n'est affiché qu'une seule fois :
+seule son empreinte est conservée en base de données.
+
+Exemple:
+  url-shortener keys create --name="equipe-marketing"`,
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		if keyNameFlag == "" {
+			fmt.Println("Erreur: Le flag --name est requis")
+			os.Exit(1)
+		}
+
+		apiKeyService, closeDB := openAPIKeyService()
+		defer closeDB()
+
+		key, secret, err := apiKeyService.CreateAPIKey(keyNameFlag)
+		if err != nil {
+			log.Fatalf("FATAL: Erreur lors de la création de la clé d'API: %v", err)
+		}
+
+		fmt.Printf("Clé d'API créée avec succès:\n")
+		fmt.Printf("ID: %d\n", key.ID)
+		fmt.Printf("Nom: %s\n", key.Name)
+		fmt.Printf("Clé: %s\n", secret)
+		fmt.Println("Conservez cette clé en lieu sûr : elle ne sera plus affichée.")
+	},
+}
+
+// KeysListCmd représente la commande 'keys list'
+var KeysListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Liste les clés d'API.",
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		apiKeyService, closeDB := openAPIKeyService()
+		defer closeDB()
+
+		keys, err := apiKeyService.ListAPIKeys()
+		if err != nil {
+			log.Fatalf("FATAL: Erreur lors de la récupération des clés d'API: %v", err)
+		}
+
+		if len(keys) == 0 {
+			fmt.Println("Aucune clé d'API.")
+			return
+		}
+
+		for _, key := range keys {
+			status := "active"
+			if key.IsRevoked() {
+				status = "révoquée le " + key.RevokedAt.Format(time.RFC3339)
+			}
+			lastUsed := "jamais"
+			if key.LastUsedAt != nil {
+				lastUsed = key.LastUsedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("  %-4d %-20s %s... (%s, dernière utilisation: %s)\n",
+				key.ID, key.Name, key.Prefix, status, lastUsed)
+		}
+	},
+}
+
+// KeysRevokeCmd représente la commande 'keys revoke'
+var KeysRevokeCmd = &cobra.Command{
+	Use:   "revoke",
+	Short: "Révoque une clé d'API.",
+	Long: `Cette commande révoque une clé d'API : elle ne permet plus d'appeler l'API.
+
+Exemple:
+  url-shortener keys revoke --id=3`,
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		if keyIDFlag == 0 {
+			fmt.Println("Erreur: Le flag --id est requis")
+			os.Exit(1)
+		}
+
+		apiKeyService, closeDB := openAPIKeyService()
+		defer closeDB()
+
+		if err := apiKeyService.RevokeAPIKey(keyIDFlag); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				fmt.Printf("Erreur: Clé d'API %d introuvable\n", keyIDFlag)
+				os.Exit(1)
+			}
+			log.Fatalf("FATAL: Erreur lors de la révocation de la clé d'API: %v", err)
+		}
+
+		fmt.Printf("Clé d'API %d révoquée.\n", keyIDFlag)
+	},
+}
+
+// openAPIKeyService ouvre la base de données et renvoie l'APIKeyService ainsi
+// qu'une fonction de fermeture de la connexion.
+func openAPIKeyService() (*services.APIKeyService, func()) {
+	if cmd.Cfg == nil {
+		log.Fatal("FATAL: La configuration n'est pas initialisée")
+	}
+
+	// Initialiser la connexion à la base de données configurée
+	db, err := database.Open(cmd.Cfg)
+	if err != nil {
+		log.Fatalf("FATAL: Échec de la connexion à la base de données: %v", err)
+	}
+
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	return services.NewAPIKeyService(apiKeyRepo), func() { database.Close(db) }
+}
+
+func init() {
+	KeysCreateCmd.Flags().StringVar(&keyNameFlag, "name", "", "Nom descriptif de la clé d'API")
+	KeysCreateCmd.MarkFlagRequired("name")
+	KeysRevokeCmd.Flags().UintVar(&keyIDFlag, "id", 0, "ID de la clé d'API à révoquer")
+	KeysRevokeCmd.MarkFlagRequired("id")
+
+	KeysCmd.AddCommand(KeysCreateCmd, KeysListCmd, KeysRevokeCmd)
+	cmd.RootCmd.AddCommand(KeysCmd)
+}
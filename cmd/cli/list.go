@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+)
+
+// Flags de la commande 'list'
+var (
+	listPageFlag     int
+	listPageSizeFlag int
+	listQueryFlag    string
+	listStatusFlag   string
+)
+
+// ListCmd représente la commande 'list'
+var ListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Liste les liens courts enregistrés.",
+	Long: `Cette commande affiche une page de liens courts, du plus récent au plus ancien,
+avec un filtre optionnel sur le code court ou l'URL longue et sur l'état d'expiration.
+
+Exemple:
+  url-shortener list --page=2 --page-size=50 --query="example.com" --status=active`,
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		if listPageFlag < 1 || listPageSizeFlag < 1 {
+			fmt.Println("Erreur: --page et --page-size doivent être supérieurs à 0")
+			os.Exit(1)
+		}
+		if listStatusFlag != "" && listStatusFlag != repository.LinkStatusActive && listStatusFlag != repository.LinkStatusExpired {
+			fmt.Println("Erreur: --status doit valoir 'active' ou 'expired'")
+			os.Exit(1)
+		}
+
+		if cmd.Cfg == nil {
+			log.Fatal("FATAL: La configuration n'est pas initialisée")
+		}
+
+		// Initialiser la connexion à la base de données configurée
+		db, err := database.Open(cmd.Cfg)
+		if err != nil {
+			log.Fatalf("FATAL: Échec de la connexion à la base de données: %v", err)
+		}
+		defer database.Close(db)
+
+		// Initialiser les repositories et services
+		linkRepo := repository.NewLinkRepository(db)
+		linkService := services.NewLinkService(linkRepo, nil)
+
+		links, total, err := linkService.ListLinks(repository.LinkFilter{
+			Query:  listQueryFlag,
+			Status: listStatusFlag,
+			Limit:  listPageSizeFlag,
+			Offset: (listPageFlag - 1) * listPageSizeFlag,
+		})
+		if err != nil {
+			log.Fatalf("FATAL: Erreur lors de la récupération des liens: %v", err)
+		}
+
+		fmt.Printf("%d lien(s) au total (page %d, %d par page):\n", total, listPageFlag, listPageSizeFlag)
+		for _, link := range links {
+			expiration := "jamais"
+			if link.ExpiresAt != nil {
+				expiration = link.ExpiresAt.Format(time.RFC3339)
+			}
+			fmt.Printf("  %-12s %s (créé le %s, expire: %s)\n",
+				link.ShortCode, link.LongURL, link.CreatedAt.Format(time.RFC3339), expiration)
+		}
+	},
+}
+
+func init() {
+	ListCmd.Flags().IntVar(&listPageFlag, "page", 1, "Numéro de la page à afficher")
+	ListCmd.Flags().IntVar(&listPageSizeFlag, "page-size", 20, "Nombre de liens par page")
+	ListCmd.Flags().StringVar(&listQueryFlag, "query", "", "Filtre sur le code court ou l'URL longue")
+	ListCmd.Flags().StringVar(&listStatusFlag, "status", "", "Filtre sur l'état du lien (active|expired)")
+	cmd.RootCmd.AddCommand(ListCmd)
+}
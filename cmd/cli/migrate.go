@@ -3,49 +3,162 @@ package cli
 import (
 	"fmt"
 	"log"
+	"strconv"
 
 	"github.com/axellelanca/urlshortener/cmd"
-	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/migrations"
 	"github.com/spf13/cobra"
-	"gorm.io/driver/sqlite"
-	"gorm.io/gorm"
-	// Driver SQLite pour GORM
 )
 
+// migrationsDirFlag stocke la valeur du flag --dir de 'migrate create'
+var migrationsDirFlag string
+
 // MigrateCmd représente la commande 'migrate'
 var MigrateCmd = &cobra.Command{
 	Use:   "migrate",
-	Short: "Exécute les migrations de la base de données pour créer ou mettre à jour les tables.",
-	Long: `Cette commande se connecte à la base de données configurée (SQLite)
-et exécute les migrations automatiques de GORM pour créer les tables 'links' et 'clicks'
-basées sur les modèles Go.`,
+	Short: "Gère les migrations versionnées du schéma de la base de données.",
+	Long: `Cette commande se connecte à la base de données configurée (SQLite, PostgreSQL ou MySQL)
+et applique les migrations SQL versionnées embarquées dans le programme. Les migrations
+appliquées sont enregistrées dans la table 'schema_migrations'.
+
+Sans sous-commande, 'migrate' équivaut à 'migrate up'.
+
+Exemples:
+  url-shortener migrate up
+  url-shortener migrate down 1
+  url-shortener migrate status
+  url-shortener migrate create add_link_tags`,
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		runMigrateUp()
+	},
+}
+
+// MigrateUpCmd applique toutes les migrations en attente.
+var MigrateUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Applique toutes les migrations en attente.",
+	Args:  cobra.NoArgs,
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		runMigrateUp()
+	},
+}
+
+// MigrateDownCmd annule les N dernières migrations appliquées.
+var MigrateDownCmd = &cobra.Command{
+	Use:   "down [N]",
+	Short: "Annule les N dernières migrations appliquées (1 par défaut).",
+	Args:  cobra.MaximumNArgs(1),
 	Run: func(cobraCmd *cobra.Command, args []string) {
-		// Utiliser la configuration globale
-		if cmd.Cfg == nil {
-			log.Fatal("FATAL: La configuration n'est pas initialisée")
+		steps := 1
+		if len(args) == 1 {
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n < 1 {
+				log.Fatalf("FATAL: Nombre de migrations invalide: '%s'", args[0])
+			}
+			steps = n
 		}
 
-		// Initialiser la connexion à la base de données SQLite avec GORM
-		db, err := gorm.Open(sqlite.Open(cmd.Cfg.Database.Name), &gorm.Config{})
+		migrator, closeDB := openMigrator()
+		defer closeDB()
+
+		reverted, err := migrator.Down(steps)
+		for _, migration := range reverted {
+			fmt.Printf("Annulée : %04d_%s\n", migration.Version, migration.Name)
+		}
 		if err != nil {
-			log.Fatalf("FATAL: Échec de la connexion à la base de données: %v", err)
+			log.Fatalf("FATAL: %v", err)
+		}
+		if len(reverted) == 0 {
+			fmt.Println("Aucune migration à annuler.")
 		}
+	},
+}
 
-		sqlDB, err := db.DB()
+// MigrateStatusCmd affiche l'état de chaque migration.
+var MigrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Affiche les migrations appliquées et en attente.",
+	Args:  cobra.NoArgs,
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		migrator, closeDB := openMigrator()
+		defer closeDB()
+
+		statuses, err := migrator.Status()
 		if err != nil {
-			log.Fatalf("FATAL: Échec de l'obtention de la base de données SQL sous-jacente: %v", err)
+			log.Fatalf("FATAL: %v", err)
 		}
-		defer sqlDB.Close()
 
-		// Exécuter les migrations automatiques de GORM
-		if err := db.AutoMigrate(&models.Link{}, &models.Click{}); err != nil {
-			log.Fatalf("FATAL: Échec des migrations: %v", err)
+		for _, status := range statuses {
+			state := "en attente"
+			if status.AppliedAt != nil {
+				state = "appliquée le " + status.AppliedAt.UTC().Format("2006-01-02 15:04:05") + " UTC"
+			}
+			fmt.Printf("  %04d_%-40s %s\n", status.Version, status.Name, state)
 		}
+	},
+}
 
-		fmt.Println("Migrations de la base de données exécutées avec succès.")
+// MigrateCreateCmd crée les fichiers d'une nouvelle migration pour chaque moteur.
+var MigrateCreateCmd = &cobra.Command{
+	Use:   "create NAME",
+	Short: "Crée les fichiers up/down d'une nouvelle migration pour chaque moteur de base de données.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		files, err := migrations.Create(migrationsDirFlag, args[0])
+		if err != nil {
+			log.Fatalf("FATAL: %v", err)
+		}
+		for _, file := range files {
+			fmt.Printf("Créé : %s\n", file)
+		}
+		fmt.Println("Complétez ces fichiers puis recompilez le programme : les migrations y sont embarquées.")
 	},
 }
 
+// runMigrateUp applique les migrations en attente et affiche celles qui ont été appliquées.
+func runMigrateUp() {
+	migrator, closeDB := openMigrator()
+	defer closeDB()
+
+	applied, err := migrator.Up()
+	for _, migration := range applied {
+		fmt.Printf("Appliquée : %04d_%s\n", migration.Version, migration.Name)
+	}
+	if err != nil {
+		log.Fatalf("FATAL: %v", err)
+	}
+
+	if len(applied) == 0 {
+		fmt.Println("Le schéma de la base de données est à jour.")
+		return
+	}
+	fmt.Println("Migrations de la base de données exécutées avec succès.")
+}
+
+// openMigrator ouvre la base de données et renvoie le Migrator ainsi
+// qu'une fonction de fermeture de la connexion.
+func openMigrator() (*migrations.Migrator, func()) {
+	if cmd.Cfg == nil {
+		log.Fatal("FATAL: La configuration n'est pas initialisée")
+	}
+
+	// Initialiser la connexion à la base de données configurée
+	db, err := database.Open(cmd.Cfg)
+	if err != nil {
+		log.Fatalf("FATAL: Échec de la connexion à la base de données: %v", err)
+	}
+
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		database.Close(db)
+		log.Fatalf("FATAL: %v", err)
+	}
+	return migrator, func() { database.Close(db) }
+}
+
 func init() {
+	MigrateCreateCmd.Flags().StringVar(&migrationsDirFlag, "dir", "internal/migrations/sql", "Répertoire source des migrations")
+	MigrateCmd.AddCommand(MigrateUpCmd, MigrateDownCmd, MigrateStatusCmd, MigrateCreateCmd)
 	cmd.RootCmd.AddCommand(MigrateCmd)
 }
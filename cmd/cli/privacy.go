@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+)
+
+// Flags de la commande 'privacy erase'
+var (
+	eraseIPFlag      string
+	eraseNetworkFlag bool
+)
+
+// PrivacyCmd représente la commande 'privacy', qui regroupe les opérations liées au RGPD.
+var PrivacyCmd = &cobra.Command{
+	Use:   "privacy",
+	Short: "Opérations liées à la protection des données personnelles (RGPD).",
+}
+
+// PrivacyEraseCmd représente la commande 'privacy erase'
+var PrivacyEraseCmd = &cobra.Command{
+	Use:   "erase",
+	Short: "Supprime tous les clics d'une personne (droit à l'effacement).",
+	Long: `Cette commande supprime tous les clics enregistrés avec l'adresse IP fournie.
+
+Lorsque l'anonymisation des IP est active, les clics sont enregistrés avec une adresse
+tronquée : utilisez --network pour supprimer aussi les clics de l'adresse anonymisée
+correspondante (attention, cela peut inclure des clics d'autres personnes du même réseau).
+Dans ce mode, le spool des clics ne contient lui aussi que des adresses tronquées.
+
+Exemple:
+  url-shortener privacy erase --ip="203.0.113.42"`,
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		if eraseIPFlag == "" {
+			fmt.Println("Erreur: Le flag --ip est requis")
+			os.Exit(1)
+		}
+
+		if cmd.Cfg == nil {
+			log.Fatal("FATAL: La configuration n'est pas initialisée")
+		}
+
+		// Initialiser la connexion à la base de données configurée
+		db, err := database.Open(cmd.Cfg)
+		if err != nil {
+			log.Fatalf("FATAL: Échec de la connexion à la base de données: %v", err)
+		}
+		defer database.Close(db)
+
+		clickRepo := repository.NewClickRepository(db)
+		clickService := services.NewClickService(clickRepo)
+
+		deleted, err := clickService.EraseClicksByIP(eraseIPFlag, eraseNetworkFlag)
+		if err != nil {
+			fmt.Printf("Erreur: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("%d clic(s) supprimé(s) pour l'adresse %s.\n", deleted, eraseIPFlag)
+	},
+}
+
+func init() {
+	PrivacyEraseCmd.Flags().StringVar(&eraseIPFlag, "ip", "", "Adresse IP de la personne concernée")
+	PrivacyEraseCmd.Flags().BoolVar(&eraseNetworkFlag, "network", false, "Supprimer aussi les clics de l'adresse anonymisée (/24 ou /48)")
+	PrivacyEraseCmd.MarkFlagRequired("ip")
+
+	PrivacyCmd.AddCommand(PrivacyEraseCmd)
+	cmd.RootCmd.AddCommand(PrivacyCmd)
+}
@@ -6,22 +6,29 @@ import (
 	"os"
 
 	"github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/database"
 	"github.com/axellelanca/urlshortener/internal/repository"
 	"github.com/axellelanca/urlshortener/internal/services"
 	"github.com/spf13/cobra"
-	"gorm.io/driver/sqlite"
-	"gorm.io/gorm"
 )
 
 // shortCodeFlag stocke la valeur du flag --code
 var shortCodeFlag string
 
+// statsTopFlag stocke la valeur du flag --top (nombre de valeurs par répartition)
+var statsTopFlag int
+
+// statsIncludeBotsFlag stocke la valeur du flag --include-bots
+var statsIncludeBotsFlag bool
+
 // StatsCmd représente la commande 'stats'
 var StatsCmd = &cobra.Command{
 	Use:   "stats",
 	Short: "Affiche les statistiques (nombre de clics) pour un lien court.",
 	Long: `Cette commande permet de récupérer et d'afficher le nombre total de clics
-pour une URL courte spécifique en utilisant son code.
+pour une URL courte spécifique en utilisant son code, ainsi que la répartition
+des clics par page d'origine (referrer), navigateur, système d'exploitation et appareil.
+Les clics de robots (robots d'indexation, aperçus de liens...) sont exclus, sauf avec --include-bots.
 
 Exemple:
   url-shortener stats --code="xyz123"`,
@@ -35,36 +42,74 @@ Exemple:
 			log.Fatal("FATAL: La configuration n'est pas initialisée")
 		}
 
-		// Initialiser la connexion à la base de données SQLite
-		db, err := gorm.Open(sqlite.Open(cmd.Cfg.Database.Name), &gorm.Config{})
+		// Initialiser la connexion à la base de données configurée
+		db, err := database.Open(cmd.Cfg)
 		if err != nil {
 			log.Fatalf("FATAL: Échec de la connexion à la base de données: %v", err)
 		}
-
-		sqlDB, err := db.DB()
-		if err != nil {
-			log.Fatalf("FATAL: Échec de l'obtention de la base de données SQL sous-jacente: %v", err)
-		}
-		defer sqlDB.Close()
+		defer database.Close(db)
 
 		// Initialiser les repositories et services
 		linkRepo := repository.NewLinkRepository(db)
-		linkService := services.NewLinkService(linkRepo)
+		clickRepo := repository.NewClickRepository(db)
+		linkService := services.NewLinkService(linkRepo, nil)
+		clickService := services.NewClickService(clickRepo)
 
 		// Récupérer les statistiques
-		link, totalClicks, err := linkService.GetLinkStats(shortCodeFlag)
+		stats, err := linkService.GetLinkStats(shortCodeFlag, nil)
 		if err != nil {
 			log.Fatalf("FATAL: Erreur lors de la récupération des statistiques: %v", err)
 		}
+		link := stats.Link
 
 		fmt.Printf("Statistiques pour le code court: %s\n", link.ShortCode)
 		fmt.Printf("URL longue: %s\n", link.LongURL)
-		fmt.Printf("Total de clics: %d\n", totalClicks)
+		fmt.Printf("Total de clics: %d\n", stats.TotalClicks(statsIncludeBotsFlag))
+		fmt.Printf("Clics humains: %d\n", stats.HumanClicks)
+		fmt.Printf("Clics de robots: %d\n", stats.BotClicks)
+
+		uniqueVisitors, err := clickService.GetUniqueVisitors(link.ID, statsIncludeBotsFlag)
+		if err != nil {
+			log.Fatalf("FATAL: Erreur lors du comptage des visiteurs uniques: %v", err)
+		}
+		fmt.Printf("Visiteurs uniques (par jour): %d\n", uniqueVisitors)
+
+		topReferrers, err := clickService.GetTopReferrers(link.ID, statsTopFlag, statsIncludeBotsFlag)
+		if err != nil {
+			log.Fatalf("FATAL: Erreur lors de la récupération des referrers: %v", err)
+		}
+		printBreakdown("Principaux referrers", topReferrers)
+
+		for _, b := range []struct{ title, dimension string }{
+			{"Navigateurs", repository.DimensionBrowser},
+			{"Systèmes d'exploitation", repository.DimensionOS},
+			{"Appareils", repository.DimensionDevice},
+		} {
+			counts, err := clickService.GetBreakdown(link.ID, b.dimension, statsTopFlag, statsIncludeBotsFlag)
+			if err != nil {
+				log.Fatalf("FATAL: Erreur lors de la récupération des statistiques: %v", err)
+			}
+			printBreakdown(b.title, counts)
+		}
 	},
 }
 
+// printBreakdown affiche une répartition des clics sous forme de liste.
+func printBreakdown(title string, counts []repository.DimensionCount) {
+	fmt.Printf("%s:\n", title)
+	if len(counts) == 0 {
+		fmt.Println("  (aucun clic)")
+		return
+	}
+	for _, count := range counts {
+		fmt.Printf("  %-30s %d\n", count.Value, count.Clicks)
+	}
+}
+
 func init() {
 	StatsCmd.Flags().StringVar(&shortCodeFlag, "code", "", "Code court de l'URL à analyser")
+	StatsCmd.Flags().IntVar(&statsTopFlag, "top", 10, "Nombre de valeurs affichées par répartition")
+	StatsCmd.Flags().BoolVar(&statsIncludeBotsFlag, "include-bots", false, "Inclure les clics de robots dans le total et les répartitions")
 	StatsCmd.MarkFlagRequired("code")
 	cmd.RootCmd.AddCommand(StatsCmd)
 }
@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+// Flags des sous-commandes de 'trash'
+var (
+	restoreCodeFlag    string
+	purgeCodeFlag      string
+	purgeOlderThanFlag time.Duration
+)
+
+// TrashCmd représente la commande 'trash', qui regroupe la gestion de la corbeille.
+var TrashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "Gère la corbeille des liens supprimés.",
+	Long: `Les liens supprimés sont placés dans la corbeille avec l'historique de leurs clics.
+Ils peuvent y être consultés, restaurés ou purgés définitivement.`,
+}
+
+// TrashListCmd représente la commande 'trash list'
+var TrashListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Liste les liens présents dans la corbeille.",
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		linkService, closeDB := openTrashLinkService()
+		defer closeDB()
+
+		links, err := linkService.ListTrashedLinks(nil)
+		if err != nil {
+			log.Fatalf("FATAL: Erreur lors de la récupération de la corbeille: %v", err)
+		}
+
+		if len(links) == 0 {
+			fmt.Println("La corbeille est vide.")
+			return
+		}
+
+		fmt.Printf("%d lien(s) dans la corbeille:\n", len(links))
+		for _, link := range links {
+			fmt.Printf("  %-12s %s (supprimé le %s)\n",
+				link.ShortCode, link.LongURL, link.DeletedAt.Time.Format(time.RFC3339))
+		}
+	},
+}
+
+// TrashRestoreCmd représente la commande 'trash restore'
+var TrashRestoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restaure un lien depuis la corbeille.",
+	Long: `Cette commande sort un lien de la corbeille : il redirige à nouveau
+et retrouve l'historique de ses clics.
+
+Exemple:
+  url-shortener trash restore --code="xyz123"`,
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		if restoreCodeFlag == "" {
+			fmt.Println("Erreur: Le flag --code est requis")
+			os.Exit(1)
+		}
+
+		linkService, closeDB := openTrashLinkService()
+		defer closeDB()
+
+		link, err := linkService.RestoreLink(restoreCodeFlag, nil)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				fmt.Printf("Erreur: Le lien '%s' n'est pas dans la corbeille\n", restoreCodeFlag)
+				os.Exit(1)
+			}
+			log.Fatalf("FATAL: Erreur lors de la restauration du lien: %v", err)
+		}
+
+		fmt.Printf("Lien '%s' restauré (%s).\n", link.ShortCode, link.LongURL)
+	},
+}
+
+// TrashPurgeCmd représente la commande 'trash purge'
+var TrashPurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Supprime définitivement des liens de la corbeille.",
+	Long: `Cette commande supprime définitivement les liens de la corbeille et leurs clics.
+Sans option, toute la corbeille est vidée.
+
+Exemples:
+  url-shortener trash purge --older-than=720h
+  url-shortener trash purge --code="xyz123"`,
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		if purgeOlderThanFlag < 0 {
+			fmt.Println("Erreur: --older-than doit être positif")
+			os.Exit(1)
+		}
+
+		linkService, closeDB := openTrashLinkService()
+		defer closeDB()
+
+		if purgeCodeFlag != "" {
+			if err := linkService.PurgeLink(purgeCodeFlag, nil); err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					fmt.Printf("Erreur: Le lien '%s' n'est pas dans la corbeille\n", purgeCodeFlag)
+					os.Exit(1)
+				}
+				log.Fatalf("FATAL: Erreur lors de la purge du lien: %v", err)
+			}
+			fmt.Printf("Lien '%s' supprimé définitivement.\n", purgeCodeFlag)
+			return
+		}
+
+		purged, err := linkService.PurgeTrash(purgeOlderThanFlag, nil)
+		if err != nil {
+			log.Fatalf("FATAL: Erreur lors de la purge de la corbeille: %v", err)
+		}
+		fmt.Printf("%d lien(s) supprimé(s) définitivement.\n", purged)
+	},
+}
+
+// openTrashLinkService ouvre la base de données et renvoie le LinkService ainsi
+// qu'une fonction de fermeture de la connexion.
+func openTrashLinkService() (*services.LinkService, func()) {
+	if cmd.Cfg == nil {
+		log.Fatal("FATAL: La configuration n'est pas initialisée")
+	}
+
+	// Initialiser la connexion à la base de données configurée
+	db, err := database.Open(cmd.Cfg)
+	if err != nil {
+		log.Fatalf("FATAL: Échec de la connexion à la base de données: %v", err)
+	}
+
+	linkRepo := repository.NewLinkRepository(db)
+	return services.NewLinkService(linkRepo, nil), func() { database.Close(db) }
+}
+
+func init() {
+	TrashRestoreCmd.Flags().StringVar(&restoreCodeFlag, "code", "", "Code court du lien à restaurer")
+	TrashRestoreCmd.MarkFlagRequired("code")
+	TrashPurgeCmd.Flags().StringVar(&purgeCodeFlag, "code", "", "Code court d'un lien précis à purger (optionnel)")
+	TrashPurgeCmd.Flags().DurationVar(&purgeOlderThanFlag, "older-than", 0, "Ne purger que les liens supprimés depuis plus longtemps, ex: 720h")
+
+	TrashCmd.AddCommand(TrashListCmd, TrashRestoreCmd, TrashPurgeCmd)
+	cmd.RootCmd.AddCommand(TrashCmd)
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/axellelanca/urlshortener/internal/urlpolicy"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+// Flags de la commande 'update'
+var (
+	updateCodeFlag   string
+	updateURLFlag    string
+	updateNotifyFlag bool
+)
+
+// UpdateCmd représente la commande 'update'
+var UpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Modifie l'URL de destination d'un lien court ou ses notifications.",
+	Long: `Cette commande remplace l'URL longue associée à un code court existant
+et/ou active ou désactive les notifications de changement d'état du moniteur.
+
+Exemple:
+  url-shortener update --code="xyz123" --url="https://www.example.com/nouvelle-page"
+  url-shortener update --code="xyz123" --notify=false`,
+	Run: func(cobraCmd *cobra.Command, args []string) {
+		var update services.LinkUpdate
+		if cobraCmd.Flags().Changed("url") {
+			update.LongURL = &updateURLFlag
+		}
+		if cobraCmd.Flags().Changed("notify") {
+			update.Notify = &updateNotifyFlag
+		}
+		if updateCodeFlag == "" || (update.LongURL == nil && update.Notify == nil) {
+			fmt.Println("Erreur: Le flag --code et au moins un des flags --url ou --notify sont requis")
+			os.Exit(1)
+		}
+
+		if cmd.Cfg == nil {
+			log.Fatal("FATAL: La configuration n'est pas initialisée")
+		}
+
+		// Initialiser la connexion à la base de données configurée
+		db, err := database.Open(cmd.Cfg)
+		if err != nil {
+			log.Fatalf("FATAL: Échec de la connexion à la base de données: %v", err)
+		}
+		defer database.Close(db)
+
+		// Charger la politique des URLs de destination
+		urlPolicy, err := urlpolicy.Load(cmd.Cfg)
+		if err != nil {
+			log.Fatalf("FATAL: Configuration url_policy invalide: %v", err)
+		}
+
+		// Initialiser les repositories et services
+		linkRepo := repository.NewLinkRepository(db)
+		linkService := services.NewLinkService(linkRepo, urlPolicy)
+
+		link, err := linkService.UpdateLink(updateCodeFlag, update, nil)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				fmt.Printf("Erreur: Code court '%s' introuvable\n", updateCodeFlag)
+				os.Exit(1)
+			}
+			if errors.Is(err, urlpolicy.ErrRejected) {
+				fmt.Printf("Erreur: %v\n", err)
+				os.Exit(1)
+			}
+			log.Fatalf("FATAL: Erreur lors de la mise à jour du lien: %v", err)
+		}
+
+		fmt.Printf("Lien mis à jour avec succès:\n")
+		fmt.Printf("Code: %s\n", link.ShortCode)
+		fmt.Printf("URL longue: %s\n", link.LongURL)
+		fmt.Printf("Notifications: %s\n", formatNotify(link.Notify))
+	},
+}
+
+// formatNotify rend lisible l'activation des notifications d'un lien.
+func formatNotify(notify bool) string {
+	if notify {
+		return "activées"
+	}
+	return "désactivées"
+}
+
+func init() {
+	UpdateCmd.Flags().StringVar(&updateCodeFlag, "code", "", "Code court du lien à modifier")
+	UpdateCmd.Flags().StringVar(&updateURLFlag, "url", "", "Nouvelle URL longue")
+	UpdateCmd.Flags().BoolVar(&updateNotifyFlag, "notify", false, "Active (true) ou désactive (false) les notifications du moniteur")
+	UpdateCmd.MarkFlagRequired("code")
+	cmd.RootCmd.AddCommand(UpdateCmd)
+}
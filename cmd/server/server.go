@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
@@ -10,15 +11,20 @@ import (
 	"time"
 
 	"github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/analytics"
 	"github.com/axellelanca/urlshortener/internal/api"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/metrics"
+	"github.com/axellelanca/urlshortener/internal/migrations"
 	"github.com/axellelanca/urlshortener/internal/monitor"
+	"github.com/axellelanca/urlshortener/internal/ratelimit"
 	"github.com/axellelanca/urlshortener/internal/repository"
 	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/axellelanca/urlshortener/internal/spool"
+	"github.com/axellelanca/urlshortener/internal/urlpolicy"
 	"github.com/axellelanca/urlshortener/internal/workers"
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/cobra"
-	"gorm.io/driver/sqlite"
-	"gorm.io/gorm"
 )
 
 // RunServerCmd représente la commande 'run-server' de Cobra.
@@ -33,41 +39,175 @@ puis lance le serveur HTTP.`,
 			log.Fatal("FATAL: La configuration n'est pas initialisée")
 		}
 
-		// Initialiser la connexion à la base de données SQLite
-		db, err := gorm.Open(sqlite.Open(cmd.Cfg.Database.Name), &gorm.Config{})
+		// Initialiser la connexion à la base de données configurée
+		db, err := database.Open(cmd.Cfg)
 		if err != nil {
 			log.Fatalf("FATAL: Échec de la connexion à la base de données: %v", err)
 		}
 
+		// Signaler un schéma qui n'est pas à jour (les migrations ne sont jamais appliquées automatiquement)
+		if migrator, err := migrations.NewMigrator(db); err != nil {
+			log.Printf("[WARN] Impossible de vérifier les migrations: %v", err)
+		} else if pending, err := migrator.Pending(); err == nil && len(pending) > 0 {
+			log.Printf("[WARN] %d migration(s) en attente : lancez 'url-shortener migrate up'.", len(pending))
+		}
+
 		// Initialiser les repositories
-		linkRepo := repository.NewLinkRepository(db)
+		var linkRepo repository.LinkRepository = repository.NewLinkRepository(db)
+		if cmd.Cfg.Cache.Enabled {
+			cachedLinkRepo := repository.NewCachedLinkRepository(linkRepo, cmd.Cfg.Cache.Size,
+				time.Duration(cmd.Cfg.Cache.TTLSeconds)*time.Second,
+				time.Duration(cmd.Cfg.Cache.NegativeTTLSeconds)*time.Second)
+			metrics.RegisterLinkCache(cachedLinkRepo.CacheStats)
+			linkRepo = cachedLinkRepo
+			log.Printf("Cache des liens activé (%d entrées maximum).", cmd.Cfg.Cache.Size)
+		}
 		clickRepo := repository.NewClickRepository(db)
+		linkCheckRepo := repository.NewLinkCheckRepository(db)
+		apiKeyRepo := repository.NewAPIKeyRepository(db)
 
 		log.Println("Repositories initialisés.")
 
+		// Charger la politique des URLs de destination (création, modification et surveillance des liens)
+		urlPolicy, err := urlpolicy.Load(cmd.Cfg)
+		if err != nil {
+			log.Fatalf("FATAL: Configuration url_policy invalide: %v", err)
+		}
+
 		// Initialiser les services métiers
-		linkService := services.NewLinkService(linkRepo)
+		linkService := services.NewLinkService(linkRepo, urlPolicy)
+		clickService := services.NewClickService(clickRepo)
+		linkHealthService := services.NewLinkHealthService(linkCheckRepo)
+
+		// Le service des clés d'API n'est injecté que si l'authentification est activée
+		var apiKeyService *services.APIKeyService
+		if cmd.Cfg.Auth.Enabled {
+			apiKeyService = services.NewAPIKeyService(apiKeyRepo)
+		} else {
+			log.Println("[WARN] Authentification désactivée : les routes /api/v1 sont accessibles sans clé d'API.")
+		}
 
 		log.Println("Services métiers initialisés.")
 
+		// Le secret des empreintes de visiteurs doit être stable d'un démarrage à l'autre
+		visitorHasher, err := analytics.NewVisitorHasher(cmd.Cfg.Analytics.VisitorSecret)
+		if err != nil {
+			log.Fatalf("FATAL: %v (générez-en un, par exemple avec 'openssl rand -hex 32', ou utilisez URLSHORTENER_VISITOR_SECRET)", err)
+		}
+
+		// Ouvrir le spool disque des clics qui ne trouvent pas de place dans le channel
+		var clickSpool *spool.Spool
+		if cmd.Cfg.Analytics.SpoolDir != "" {
+			clickSpool, err = spool.Open(cmd.Cfg.Analytics.SpoolDir, int64(cmd.Cfg.Analytics.SpoolSegmentKB)*1024)
+			if err != nil {
+				log.Fatalf("FATAL: Échec de l'ouverture du spool des clics: %v", err)
+			}
+		} else {
+			log.Println("[WARN] Spool des clics désactivé : les clics reçus quand le channel est plein seront perdus.")
+		}
+
 		// Configurer le routeur Gin et les handlers API
-		router := gin.Default()
-		api.SetupRoutes(router, linkService, cmd.Cfg.Analytics.BufferSize)
+		// Le logger d'accès remplace celui de gin.Default() pour pouvoir anonymiser les IP journalisées
+		router := gin.New()
+		router.Use(api.AccessLogger(cmd.Cfg.Privacy.AnonymizeIP), gin.Recovery())
+		if err := router.SetTrustedProxies(cmd.Cfg.Server.TrustedProxies); err != nil {
+			log.Fatalf("FATAL: server.trusted_proxies invalide: %v", err)
+		}
+		routeOptions := api.RouteOptions{
+			APIKeyService: apiKeyService,
+			ClickSpool:    clickSpool,
+			AnonymizeIP:   cmd.Cfg.Privacy.AnonymizeIP,
+			BufferSize:    cmd.Cfg.Analytics.BufferSize,
+			ExposeMetrics: cmd.Cfg.Metrics.Enabled,
+		}
+		if cmd.Cfg.RateLimit.Enabled {
+			createLimit, redirectLimit := cmd.Cfg.RateLimit.Create, cmd.Cfg.RateLimit.Redirect
+			routeOptions.CreateLimiter = ratelimit.NewLimiter(createLimit.RequestsPerMinute, createLimit.Burst)
+			routeOptions.RedirectLimiter = ratelimit.NewLimiter(redirectLimit.RequestsPerMinute, redirectLimit.Burst)
+			log.Printf("Limitation de débit activée : création %d req/min (rafale %d), redirection %d req/min (rafale %d).",
+				createLimit.RequestsPerMinute, createLimit.Burst, redirectLimit.RequestsPerMinute, redirectLimit.Burst)
+		}
+		api.SetupRoutes(router, linkService, clickService, linkHealthService, routeOptions)
 
 		log.Println("Routes API configurées.")
 
 		// Récupérer le channel des événements de clic et lancer les workers
 		clickEvents := api.GetClickEventsChannel()
-		workers.StartClickWorkers(clickEvents, clickRepo, cmd.Cfg.Analytics.WorkerCount)
+		metrics.RegisterClickQueue(clickEvents)
+		clickWorkerOptions := workers.ClickWorkerOptions{
+			WorkerCount:   cmd.Cfg.Analytics.WorkerCount,
+			BatchSize:     cmd.Cfg.Analytics.BatchSize,
+			FlushInterval: time.Duration(cmd.Cfg.Analytics.FlushInterval) * time.Millisecond,
+			Hasher:        visitorHasher,
+			AnonymizeIP:   cmd.Cfg.Privacy.AnonymizeIP,
+		}
+		clickWorkers := workers.StartClickWorkers(clickEvents, clickRepo, clickWorkerOptions)
 
 		log.Printf("Channel d'événements de clic initialisé avec un buffer de %d. %d worker(s) de clics démarré(s).",
 			cmd.Cfg.Analytics.BufferSize, cmd.Cfg.Analytics.WorkerCount)
 
+		// Rejouer les clics du spool (y compris ceux laissés par une exécution précédente)
+		var spoolReplayer *workers.SpoolReplayer
+		if clickSpool != nil {
+			if cmd.Cfg.Analytics.SpoolReplaySeconds <= 0 {
+				log.Fatalf("FATAL: analytics.spool_replay_interval_seconds invalide: %d (doit être supérieur à 0)",
+					cmd.Cfg.Analytics.SpoolReplaySeconds)
+			}
+			spoolReplayer = workers.NewSpoolReplayer(clickSpool, clickEvents, clickRepo, clickWorkerOptions,
+				time.Duration(cmd.Cfg.Analytics.SpoolReplaySeconds)*time.Second)
+			go spoolReplayer.Start()
+		}
+
 		// Initialiser et lancer le moniteur d'URLs
 		monitorInterval := time.Duration(cmd.Cfg.Monitor.IntervalMinutes) * time.Minute
-		urlMonitor := monitor.NewUrlMonitor(linkRepo, monitorInterval)
+		notifiers, err := monitor.LoadNotifiers(cmd.Cfg)
+		if err != nil {
+			log.Fatalf("FATAL: Configuration des notifications invalide: %v", err)
+		}
+		urlMonitor := monitor.NewUrlMonitor(linkRepo, linkCheckRepo, monitor.Options{
+			Interval:               monitorInterval,
+			Workers:                cmd.Cfg.Monitor.Workers,
+			PerHostLimit:           cmd.Cfg.Monitor.PerHostConcurrency,
+			MaxRedirects:           cmd.Cfg.Monitor.MaxRedirects,
+			CertExpiryWarning:      time.Duration(cmd.Cfg.Monitor.CertExpiryDays) * 24 * time.Hour,
+			ContentMaxBytes:        int64(cmd.Cfg.Monitor.ContentMaxKB) << 10,
+			ContentChangeThreshold: cmd.Cfg.Monitor.ContentChangeBits,
+			HistoryRetention:       time.Duration(cmd.Cfg.Monitor.HistoryDays) * 24 * time.Hour,
+			URLPolicy:              urlPolicy,
+			Notifiers:              notifiers,
+		})
 		go urlMonitor.Start()
-		log.Printf("Moniteur d'URLs démarré avec un intervalle de %v.", monitorInterval)
+		log.Printf("Moniteur d'URLs démarré avec un intervalle de %v (%d canal(aux) de notification).", monitorInterval, len(notifiers))
+
+		// Lancer la purge périodique des liens expirés
+		if cmd.Cfg.Expiration.SweepIntervalMinutes <= 0 {
+			log.Fatalf("FATAL: expiration.sweep_interval_minutes invalide: %d (doit être supérieur à 0)",
+				cmd.Cfg.Expiration.SweepIntervalMinutes)
+		}
+		sweepInterval := time.Duration(cmd.Cfg.Expiration.SweepIntervalMinutes) * time.Minute
+		purgeAfter := time.Duration(cmd.Cfg.Expiration.PurgeAfterHours) * time.Hour
+		sweeper := workers.NewExpirationSweeper(linkRepo, sweepInterval, purgeAfter)
+		go sweeper.Start()
+		log.Printf("Purge des liens expirés démarrée avec un intervalle de %v.", sweepInterval)
+
+		// Lancer la politique de rétention des clics si elle est configurée
+		var retentionJob *workers.RetentionJob
+		if cmd.Cfg.Privacy.RetentionDays > 0 {
+			retentionMode := cmd.Cfg.Privacy.RetentionMode
+			if retentionMode != workers.RetentionModeDelete && retentionMode != workers.RetentionModeAnonymize {
+				log.Fatalf("FATAL: privacy.retention_mode invalide: '%s' (delete|anonymize)", retentionMode)
+			}
+			if cmd.Cfg.Privacy.RetentionIntervalHours <= 0 {
+				log.Fatalf("FATAL: privacy.retention_interval_hours invalide: %d (doit être supérieur à 0)",
+					cmd.Cfg.Privacy.RetentionIntervalHours)
+			}
+			retentionJob = workers.NewRetentionJob(clickRepo,
+				time.Duration(cmd.Cfg.Privacy.RetentionIntervalHours)*time.Hour,
+				time.Duration(cmd.Cfg.Privacy.RetentionDays)*24*time.Hour,
+				retentionMode)
+			go retentionJob.Start()
+			log.Printf("Rétention des clics activée : %d jour(s), mode %s.", cmd.Cfg.Privacy.RetentionDays, retentionMode)
+		}
 
 		// Créer le serveur HTTP Gin
 		serverAddr := fmt.Sprintf(":%d", cmd.Cfg.Server.Port)
@@ -76,23 +216,87 @@ puis lance le serveur HTTP.`,
 			Handler: router,
 		}
 
-		// Gérer l'arrêt gracieux
+		// Démarrer le serveur dans une goroutine pour pouvoir orchestrer l'arrêt ici
+		serverErrors := make(chan error, 1)
 		go func() {
-			quit := make(chan os.Signal, 1)
-			signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-			<-quit
-
-			log.Println("Arrêt du serveur...")
-			if err := srv.Close(); err != nil {
-				log.Printf("Erreur lors de la fermeture du serveur: %v", err)
+			log.Printf("Serveur démarré sur %s", serverAddr)
+			if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				serverErrors <- err
 			}
 		}()
 
-		// Démarrer le serveur
-		log.Printf("Serveur démarré sur %s", serverAddr)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		// Attendre un signal d'arrêt
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+		select {
+		case err := <-serverErrors:
 			log.Fatalf("FATAL: Erreur lors du démarrage du serveur: %v", err)
+		case sig := <-quit:
+			log.Printf("Signal %v reçu, arrêt du serveur...", sig)
+		}
+
+		// 1. Arrêter d'accepter des requêtes et laisser se terminer celles en cours
+		shutdownTimeout := time.Duration(cmd.Cfg.Server.ShutdownTimeoutSeconds) * time.Second
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			log.Printf("[WARN] Requêtes encore en cours après %v, fermeture forcée: %v", shutdownTimeout, err)
+			srv.Close()
+		}
+		log.Println("Serveur HTTP arrêté.")
+
+		// 2. Fermer le channel des clics puis attendre que les workers aient enregistré les événements restants
+		pending := len(clickEvents)
+		api.CloseClickEventsChannel()
+		log.Printf("Channel des clics fermé (%d événement(s) en attente), vidage des workers...", pending)
+
+		drainTimeout := time.Duration(cmd.Cfg.Server.DrainTimeoutSeconds) * time.Second
+		drained := make(chan struct{})
+		go func() {
+			clickWorkers.Wait()
+			close(drained)
+		}()
+		workersStopped := true
+		select {
+		case <-drained:
+			log.Println("Workers de clics arrêtés, tous les événements ont été enregistrés.")
+		case <-time.After(drainTimeout):
+			// Les événements encore dans le channel sont confiés au spool et rejoués au prochain démarrage
+			spooled, lost := workers.SpoolPendingClicks(clickEvents, clickSpool)
+			log.Printf("[WARN] Délai de %v dépassé : %d événement(s) de clic placé(s) dans le spool, %d perdu(s). Attente des écritures en cours...",
+				drainTimeout, spooled, lost)
+			select {
+			case <-drained:
+				log.Println("Workers de clics arrêtés.")
+			case <-time.After(drainTimeout):
+				// Fermer la base sous des workers encore en train d'écrire ferait échouer leurs lots en cours
+				workersStopped = false
+				log.Printf("[WARN] Workers de clics toujours en cours d'écriture après %v : la base de données ne sera pas fermée.", 2*drainTimeout)
+			}
+		}
+
+		// 3. Arrêter les tâches de fond ; le spool est scellé et sera rejoué au prochain démarrage
+		if spoolReplayer != nil {
+			spoolReplayer.Stop()
+		}
+		if clickSpool != nil {
+			if err := clickSpool.Close(); err != nil {
+				log.Printf("Erreur lors de la fermeture du spool des clics: %v", err)
+			}
+		}
+		urlMonitor.Stop()
+		sweeper.Stop()
+		if retentionJob != nil {
+			retentionJob.Stop()
+		}
+
+		// 4. Fermer la base de données en dernier, une fois les workers de clics arrêtés
+		if workersStopped {
+			if err := database.Close(db); err != nil {
+				log.Printf("Erreur lors de la fermeture de la base de données: %v", err)
+			}
 		}
+		log.Println("Arrêt terminé.")
 	},
 }
 
@@ -0,0 +1,65 @@
+package analytics
+
+import (
+	"net/http"
+	"strings"
+)
+
+// botSignatures contient des fragments (en minuscules) caractéristiques des User-Agents
+// de robots : moteurs de recherche, générateurs d'aperçus des messageries et réseaux sociaux,
+// outils de surveillance.
+var botSignatures = []string{
+	// Termes génériques
+	"bot", "crawler", "spider", "crawl", "slurp", "scraper", "headlesschrome", "preview",
+	// Moteurs de recherche
+	"googlebot", "bingbot", "yandex", "baiduspider", "duckduckbot", "applebot", "petalbot",
+	// Générateurs d'aperçus (unfurlers)
+	"facebookexternalhit", "facebookcatalog", "twitterbot", "linkedinbot", "slackbot",
+	"slack-imgproxy", "discordbot", "telegrambot", "whatsapp", "skypeuripreview",
+	"embedly", "pinterest", "redditbot", "mastodon", "iframely", "vkshare",
+	// Surveillance et outils
+	"uptimerobot", "pingdom", "statuscake", "site24x7", "lighthouse",
+}
+
+// prefetchHeaders liste les en-têtes (et valeurs, en minuscules) envoyés par les navigateurs
+// lorsqu'ils préchargent une page sans action de l'utilisateur.
+var prefetchHeaders = map[string][]string{
+	"Purpose":     {"prefetch", "preview"},
+	"Sec-Purpose": {"prefetch", "prefetch;prerender"},
+	"X-Purpose":   {"preview", "prefetch"},
+	"X-Moz":       {"prefetch"},
+}
+
+// IsBotUserAgent indique si un User-Agent correspond à un robot connu.
+func IsBotUserAgent(ua string) bool {
+	lower := strings.ToLower(ua)
+	for _, signature := range botSignatures {
+		if strings.Contains(lower, signature) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsBotRequest indique si une requête de redirection ne provient vraisemblablement pas
+// d'un humain : User-Agent de robot, requête HEAD ou préchargement par le navigateur.
+func IsBotRequest(r *http.Request) bool {
+	if r.Method == http.MethodHead {
+		return true
+	}
+	if IsBotUserAgent(r.UserAgent()) {
+		return true
+	}
+	for header, values := range prefetchHeaders {
+		got := strings.ToLower(strings.TrimSpace(r.Header.Get(header)))
+		if got == "" {
+			continue
+		}
+		for _, value := range values {
+			if got == value {
+				return true
+			}
+		}
+	}
+	return false
+}
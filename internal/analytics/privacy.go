@@ -0,0 +1,22 @@
+package analytics
+
+import "net"
+
+// Tailles des préfixes conservés lors de l'anonymisation des adresses IP.
+const (
+	ipv4AnonymizedBits = 24
+	ipv6AnonymizedBits = 48
+)
+
+// AnonymizeIP tronque une adresse IP : les IPv4 sont réduites à leur /24 (dernier octet à zéro)
+// et les IPv6 à leur /48. Une valeur qui n'est pas une adresse IP valide est remplacée par une chaîne vide.
+func AnonymizeIP(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ""
+	}
+	if v4 := parsed.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(ipv4AnonymizedBits, 32)).String()
+	}
+	return parsed.Mask(net.CIDRMask(ipv6AnonymizedBits, 128)).String()
+}
@@ -0,0 +1,27 @@
+package analytics
+
+import (
+	"net/url"
+	"strings"
+)
+
+// NormalizeReferrer réduit un en-tête Referer à son nom d'hôte (en minuscules, sans port ni "www.").
+// Renvoie une chaîne vide si l'en-tête est absent ou inexploitable (trafic direct).
+func NormalizeReferrer(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Hostname() == "" {
+		return ""
+	}
+
+	host := strings.ToLower(u.Hostname())
+	host = strings.TrimPrefix(host, "www.")
+	if len(host) > 255 {
+		host = host[:255]
+	}
+	return host
+}
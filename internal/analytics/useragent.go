@@ -0,0 +1,139 @@
+package analytics
+
+import "strings"
+
+// Classes d'appareil reconnues.
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceBot     = "bot"
+)
+
+// UserAgentInfo regroupe les dimensions extraites d'un en-tête User-Agent.
+// Les champs sont vides lorsque l'information n'a pas pu être déterminée.
+type UserAgentInfo struct {
+	Browser        string // Famille du navigateur (ex: Chrome, Firefox)
+	BrowserVersion string // Version majeure du navigateur (ex: 120)
+	OS             string // Famille du système d'exploitation (ex: Windows, iOS)
+	Device         string // Classe d'appareil : desktop, mobile, tablet ou bot
+}
+
+// browserSignature associe un marqueur présent dans le User-Agent à une famille de navigateur.
+// La version est lue juste après le marqueur.
+type browserSignature struct {
+	token  string
+	family string
+}
+
+// browserSignatures est parcourue dans l'ordre : les navigateurs basés sur Chromium
+// annoncent aussi "Chrome/" et "Safari/", ils doivent donc être testés en premier.
+var browserSignatures = []browserSignature{
+	{"EdgiOS/", "Edge"},
+	{"EdgA/", "Edge"},
+	{"Edg/", "Edge"},
+	{"Edge/", "Edge"},
+	{"OPR/", "Opera"},
+	{"Opera/", "Opera"},
+	{"SamsungBrowser/", "Samsung Internet"},
+	{"YaBrowser/", "Yandex Browser"},
+	{"Vivaldi/", "Vivaldi"},
+	{"FxiOS/", "Firefox"},
+	{"Firefox/", "Firefox"},
+	{"CriOS/", "Chrome"},
+	{"Chrome/", "Chrome"},
+	{"Version/", "Safari"}, // Safari annonce sa version via "Version/x.y ... Safari/"
+	{"MSIE ", "Internet Explorer"},
+	{"curl/", "curl"},
+	{"Wget/", "Wget"},
+	{"python-requests/", "Python Requests"},
+	{"Go-http-client/", "Go HTTP client"},
+}
+
+// osSignature associe un marqueur présent dans le User-Agent à une famille de système.
+type osSignature struct {
+	token  string
+	family string
+}
+
+// osSignatures est parcourue dans l'ordre : Android annonce "Linux", iOS annonce "like Mac OS X".
+var osSignatures = []osSignature{
+	{"Windows Phone", "Windows Phone"},
+	{"Windows", "Windows"},
+	{"Android", "Android"},
+	{"iPhone", "iOS"},
+	{"iPad", "iOS"},
+	{"iPod", "iOS"},
+	{"CrOS", "ChromeOS"},
+	{"Macintosh", "macOS"},
+	{"Mac OS X", "macOS"},
+	{"Linux", "Linux"},
+}
+
+// ParseUserAgent extrait le navigateur, le système d'exploitation et la classe d'appareil
+// d'un en-tête User-Agent à l'aide de signatures simples.
+func ParseUserAgent(ua string) UserAgentInfo {
+	ua = strings.TrimSpace(ua)
+	if ua == "" {
+		return UserAgentInfo{}
+	}
+
+	info := UserAgentInfo{}
+	info.Browser, info.BrowserVersion = parseBrowser(ua)
+
+	for _, sig := range osSignatures {
+		if strings.Contains(ua, sig.token) {
+			info.OS = sig.family
+			break
+		}
+	}
+
+	info.Device = parseDevice(ua)
+	return info
+}
+
+// parseBrowser renvoie la famille et la version majeure du navigateur.
+func parseBrowser(ua string) (string, string) {
+	if strings.Contains(ua, "Trident/") && strings.Contains(ua, "rv:") {
+		return "Internet Explorer", majorVersion(ua[strings.Index(ua, "rv:")+len("rv:"):])
+	}
+	for _, sig := range browserSignatures {
+		idx := strings.Index(ua, sig.token)
+		if idx < 0 {
+			continue
+		}
+		if sig.family == "Safari" && !strings.Contains(ua, "Safari/") {
+			continue
+		}
+		return sig.family, majorVersion(ua[idx+len(sig.token):])
+	}
+	return "", ""
+}
+
+// majorVersion lit la version majeure (chiffres initiaux) au début d'une chaîne.
+func majorVersion(s string) string {
+	end := 0
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	return s[:end]
+}
+
+// parseDevice détermine la classe d'appareil à partir du User-Agent.
+func parseDevice(ua string) string {
+	if IsBotUserAgent(ua) {
+		return DeviceBot
+	}
+
+	lower := strings.ToLower(ua)
+	switch {
+	case strings.Contains(ua, "iPad"), strings.Contains(lower, "tablet"),
+		strings.Contains(ua, "Android") && !strings.Contains(ua, "Mobile"):
+		return DeviceTablet
+	case strings.Contains(ua, "Mobi"), strings.Contains(ua, "iPhone"), strings.Contains(ua, "iPod"),
+		strings.Contains(ua, "Windows Phone"):
+		return DeviceMobile
+	default:
+		return DeviceDesktop
+	}
+}
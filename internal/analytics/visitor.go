@@ -0,0 +1,44 @@
+package analytics
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// visitorHashLength est le nombre de caractères hexadécimaux conservés pour l'empreinte d'un visiteur.
+const visitorHashLength = 32
+
+// VisitorHasher calcule une empreinte pseudonyme d'un visiteur à partir de son IP et de son User-Agent.
+// Le sel change chaque jour (UTC) : un même visiteur ne peut pas être suivi d'un jour à l'autre,
+// et l'empreinte ne permet pas de retrouver l'adresse IP sans connaître le secret.
+type VisitorHasher struct {
+	secret []byte
+}
+
+// NewVisitorHasher crée un VisitorHasher à partir d'un secret. Le secret est obligatoire : un secret
+// changeant à chaque démarrage rendrait les empreintes incohérentes d'un redémarrage à l'autre.
+func NewVisitorHasher(secret string) (*VisitorHasher, error) {
+	if secret == "" {
+		return nil, errors.New("analytics.visitor_secret est requis pour calculer les empreintes des visiteurs uniques")
+	}
+	return &VisitorHasher{secret: []byte(secret)}, nil
+}
+
+// dailySalt dérive le sel du jour (UTC) de l'instant donné.
+func (h *VisitorHasher) dailySalt(at time.Time) []byte {
+	mac := hmac.New(sha256.New, h.secret)
+	mac.Write([]byte(at.UTC().Format("2006-01-02")))
+	return mac.Sum(nil)
+}
+
+// Hash renvoie l'empreinte du visiteur (IP + User-Agent) pour le jour de l'instant donné.
+func (h *VisitorHasher) Hash(ip, userAgent string, at time.Time) string {
+	mac := hmac.New(sha256.New, h.dailySalt(at))
+	mac.Write([]byte(ip))
+	mac.Write([]byte{0})
+	mac.Write([]byte(userAgent))
+	return hex.EncodeToString(mac.Sum(nil))[:visitorHashLength]
+}
@@ -0,0 +1,32 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewVisitorHasherRequiresSecret(t *testing.T) {
+	if _, err := NewVisitorHasher(""); err == nil {
+		t.Error("secret vide accepté")
+	}
+}
+
+func TestVisitorHashIsStableAcrossRestarts(t *testing.T) {
+	at := time.Date(2026, time.October, 14, 9, 0, 0, 0, time.UTC)
+	first, err := NewVisitorHasher("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Un nouveau calculateur créé avec le même secret (redémarrage du serveur) donne les mêmes empreintes
+	restarted, _ := NewVisitorHasher("secret")
+	if first.Hash("203.0.113.1", "Firefox", at) != restarted.Hash("203.0.113.1", "Firefox", at) {
+		t.Error("empreintes différentes après un redémarrage avec le même secret")
+	}
+	if first.Hash("203.0.113.1", "Firefox", at) == first.Hash("203.0.113.1", "Firefox", at.Add(24*time.Hour)) {
+		t.Error("empreinte identique d'un jour à l'autre : le sel quotidien n'est pas appliqué")
+	}
+	other, _ := NewVisitorHasher("autre secret")
+	if first.Hash("203.0.113.1", "Firefox", at) == other.Hash("203.0.113.1", "Firefox", at) {
+		t.Error("empreinte indépendante du secret")
+	}
+}
@@ -2,13 +2,23 @@ package api
 
 import (
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"strconv"
+	"sync"
 	"time"
 
+	"github.com/axellelanca/urlshortener/internal/analytics"
+	"github.com/axellelanca/urlshortener/internal/metrics"
 	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/ratelimit"
+	"github.com/axellelanca/urlshortener/internal/repository"
 	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/axellelanca/urlshortener/internal/spool"
+	"github.com/axellelanca/urlshortener/internal/urlpolicy"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"gorm.io/gorm"
 	// Pour gérer gorm.ErrRecordNotFound
 )
@@ -17,30 +27,109 @@ import (
 // aux workers asynchrones. Il est bufferisé pour ne pas bloquer les requêtes de redirection.
 var ClickEventsChannel chan models.ClickEvent
 
+// clickEventsMu protège la fermeture du channel des clics : aucun envoi ne doit avoir lieu
+// une fois le channel fermé lors de l'arrêt du serveur.
+var (
+	clickEventsMu     sync.RWMutex
+	clickEventsClosed bool
+)
+
 // GetClickEventsChannel retourne le channel global des événements de clic.
 // Cette fonction est utilisée par les workers pour s'assurer qu'ils utilisent le bon channel.
 func GetClickEventsChannel() chan models.ClickEvent {
 	return ClickEventsChannel
 }
 
-// SetupRoutes configure toutes les routes de l'API Gin et injecte les dépendances nécessaires
-func SetupRoutes(router *gin.Engine, linkService *services.LinkService, bufferSize int) {
+// CloseClickEventsChannel ferme le channel des événements de clic afin que les workers
+// terminent le traitement des événements restants puis s'arrêtent.
+// Les clics reçus après la fermeture sont ignorés. Les appels suivants sont sans effet.
+func CloseClickEventsChannel() {
+	clickEventsMu.Lock()
+	defer clickEventsMu.Unlock()
+	if clickEventsClosed || ClickEventsChannel == nil {
+		return
+	}
+	clickEventsClosed = true
+	close(ClickEventsChannel)
+}
+
+// sendClickEvent envoie un événement de clic au channel sans bloquer la requête.
+// Il renvoie false si le channel est plein ou déjà fermé (arrêt du serveur en cours).
+func sendClickEvent(event models.ClickEvent) bool {
+	clickEventsMu.RLock()
+	defer clickEventsMu.RUnlock()
+	if clickEventsClosed {
+		return false
+	}
+	select {
+	case ClickEventsChannel <- event:
+		return true
+	default:
+		return false
+	}
+}
+
+// RouteOptions regroupe les dépendances facultatives des routes. Une valeur nil désactive la fonctionnalité.
+type RouteOptions struct {
+	APIKeyService   *services.APIKeyService // Exige une clé d'API sur /api/v1
+	ClickSpool      *spool.Spool            // Conserve les clics qui ne trouvent pas de place dans le channel
+	AnonymizeIP     bool                    // Tronque l'IP des clics dès leur réception (mode RGPD)
+	BufferSize      int                     // Taille du buffer du channel des clics
+	ExposeMetrics   bool                    // Expose les métriques Prometheus sur /metrics
+	CreateLimiter   *ratelimit.Limiter      // Limite le débit de création de liens
+	RedirectLimiter *ratelimit.Limiter      // Limite le débit des redirections
+}
+
+// SetupRoutes configure toutes les routes de l'API Gin et injecte les dépendances nécessaires.
+func SetupRoutes(router *gin.Engine, linkService *services.LinkService, clickService *services.ClickService, linkHealthService *services.LinkHealthService, opts RouteOptions) {
 	// Initialiser le channel avec la taille du buffer configurée
-	ClickEventsChannel = make(chan models.ClickEvent, bufferSize)
-	log.Printf("[DEBUG] Channel des événements de clic initialisé avec un buffer de %d", bufferSize)
+	clickEventsMu.Lock()
+	ClickEventsChannel = make(chan models.ClickEvent, opts.BufferSize)
+	clickEventsClosed = false
+	clickEventsMu.Unlock()
+	log.Printf("[DEBUG] Channel des événements de clic initialisé avec un buffer de %d", opts.BufferSize)
 
 	// Route de Health Check
 	router.GET("/health", HealthCheckHandler)
 
+	// Métriques Prometheus
+	if opts.ExposeMetrics {
+		router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	}
+
 	// Routes de l'API
 	v1 := router.Group("/api/v1")
+	if opts.APIKeyService != nil {
+		v1.Use(APIKeyAuthMiddleware(opts.APIKeyService))
+	}
 	{
-		v1.POST("/links", CreateShortLinkHandler(linkService))
-		v1.GET("/links/:shortCode/stats", GetLinkStatsHandler(linkService))
+		createHandlers := []gin.HandlerFunc{CreateShortLinkHandler(linkService)}
+		if opts.CreateLimiter != nil {
+			createHandlers = append([]gin.HandlerFunc{RateLimitMiddleware(opts.CreateLimiter, "create")}, createHandlers...)
+		}
+		v1.POST("/links", createHandlers...)
+		v1.GET("/links", ListLinksHandler(linkService))
+		v1.GET("/links/:shortCode", GetLinkHandler(linkService))
+		v1.PATCH("/links/:shortCode", UpdateLinkHandler(linkService))
+		v1.DELETE("/links/:shortCode", DeleteLinkHandler(linkService))
+		v1.GET("/links/:shortCode/stats", GetLinkStatsHandler(linkService, clickService))
+		v1.GET("/links/:shortCode/stats/timeseries", GetLinkTimeSeriesHandler(linkService, clickService))
+		v1.GET("/links/:shortCode/health", GetLinkHealthHandler(linkService, linkHealthService))
+
+		// Corbeille : liens supprimés, restaurables jusqu'à leur purge
+		v1.GET("/trash", ListTrashHandler(linkService))
+		v1.DELETE("/trash", PurgeTrashHandler(linkService))
+		v1.POST("/trash/:shortCode/restore", RestoreLinkHandler(linkService))
+		v1.DELETE("/trash/:shortCode", PurgeLinkHandler(linkService))
 	}
 
 	// Route de Redirection
-	router.GET("/:shortCode", RedirectHandler(linkService))
+	redirectHandlers := []gin.HandlerFunc{RedirectHandler(linkService, opts.ClickSpool, opts.AnonymizeIP)}
+	if opts.RedirectLimiter != nil {
+		redirectHandlers = append([]gin.HandlerFunc{RateLimitMiddleware(opts.RedirectLimiter, "redirect")}, redirectHandlers...)
+	}
+	router.GET("/:shortCode", redirectHandlers...)
+	router.HEAD("/:shortCode", redirectHandlers...)
 }
 
 // HealthCheckHandler gère la route /health pour vérifier l'état du service
@@ -52,7 +141,11 @@ func HealthCheckHandler(c *gin.Context) {
 
 // CreateLinkRequest représente le corps de la requête JSON pour la création d'un lien
 type CreateLinkRequest struct {
-	LongURL string `json:"long_url" binding:"required,url"`
+	LongURL    string     `json:"long_url" binding:"required,url"`
+	CustomCode string     `json:"custom_code"` // Alias optionnel choisi par l'utilisateur
+	ExpiresAt  *time.Time `json:"expires_at"`  // Date d'expiration absolue (RFC 3339)
+	TTL        string     `json:"ttl"`         // Durée de vie relative (ex: "72h")
+	Notify     bool       `json:"notify"`      // Notifier les changements d'état de la destination
 }
 
 // CreateShortLinkHandler gère la création d'une URL courte
@@ -64,22 +157,274 @@ func CreateShortLinkHandler(linkService *services.LinkService) gin.HandlerFunc {
 			return
 		}
 
-		link, err := linkService.CreateLink(req.LongURL)
+		var ttl time.Duration
+		if req.TTL != "" {
+			d, err := time.ParseDuration(req.TTL)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "TTL invalide (ex: \"24h\", \"90m\")"})
+				return
+			}
+			ttl = d
+		}
+
+		expiresAt, err := services.ResolveExpiration(req.ExpiresAt, ttl, time.Now())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		link, err := linkService.CreateLink(req.LongURL, services.CreateLinkOptions{
+			CustomCode: req.CustomCode,
+			ExpiresAt:  expiresAt,
+			OwnerKeyID: callerKeyID(c),
+			Notify:     req.Notify,
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, services.ErrShortCodeTaken):
+				c.JSON(http.StatusConflict, gin.H{"error": "Ce code court est déjà utilisé"})
+			case errors.Is(err, services.ErrInvalidShortCode), errors.Is(err, services.ErrReservedShortCode),
+				errors.Is(err, urlpolicy.ErrRejected):
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la création du lien"})
+			}
+			return
+		}
+
+		metrics.LinksCreated.Inc()
+		c.JSON(http.StatusCreated, linkResponse(link))
+	}
+}
+
+// Paramètres de pagination par défaut pour la liste des liens.
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// linkResponse construit la représentation JSON d'un lien renvoyée par l'API.
+func linkResponse(link *models.Link) gin.H {
+	resp := gin.H{
+		"short_code": link.ShortCode,
+		"long_url":   link.LongURL,
+		"created_at": link.CreatedAt,
+		"expires_at": link.ExpiresAt,
+		"notify":     link.Notify,
+	}
+	if link.DeletedAt.Valid {
+		resp["deleted_at"] = link.DeletedAt.Time
+	}
+	return resp
+}
+
+// ListLinksHandler gère la liste paginée des liens.
+// Paramètres de requête : page, page_size, q (recherche) et status (active|expired).
+func ListLinksHandler(linkService *services.LinkService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+		if err != nil || page < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Paramètre 'page' invalide"})
+			return
+		}
+
+		pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", strconv.Itoa(defaultPageSize)))
+		if err != nil || pageSize < 1 || pageSize > maxPageSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Paramètre 'page_size' invalide (1-%d)", maxPageSize)})
+			return
+		}
+
+		status := c.Query("status")
+		if status != "" && status != repository.LinkStatusActive && status != repository.LinkStatusExpired {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Paramètre 'status' invalide (active|expired)"})
+			return
+		}
+
+		links, total, err := linkService.ListLinks(repository.LinkFilter{
+			Query:      c.Query("q"),
+			Status:     status,
+			Limit:      pageSize,
+			Offset:     (page - 1) * pageSize,
+			OwnerKeyID: callerKeyID(c),
+		})
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la création du lien"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération des liens"})
 			return
 		}
 
-		c.JSON(http.StatusCreated, gin.H{
-			"short_code": link.ShortCode,
-			"long_url":   link.LongURL,
+		items := make([]gin.H, 0, len(links))
+		for i := range links {
+			items = append(items, linkResponse(&links[i]))
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"links":     items,
+			"page":      page,
+			"page_size": pageSize,
+			"total":     total,
 		})
 	}
 }
 
-// RedirectHandler gère la redirection des URLs courtes vers leurs URLs longues
-func RedirectHandler(linkService *services.LinkService) gin.HandlerFunc {
+// GetLinkHandler gère la récupération d'un lien par son code court.
+func GetLinkHandler(linkService *services.LinkService) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		link, err := linkService.GetOwnedLink(c.Param("shortCode"), callerKeyID(c))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Code court non trouvé"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération du lien"})
+			return
+		}
+
+		c.JSON(http.StatusOK, linkResponse(link))
+	}
+}
+
+// UpdateLinkRequest représente le corps de la requête JSON pour la modification d'un lien.
+// Les champs absents ne sont pas modifiés.
+type UpdateLinkRequest struct {
+	LongURL *string `json:"long_url" binding:"omitempty,url"`
+	Notify  *bool   `json:"notify"`
+}
+
+// UpdateLinkHandler gère la modification de l'URL de destination d'un lien et de ses notifications.
+func UpdateLinkHandler(linkService *services.LinkService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req UpdateLinkRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "URL invalide"})
+			return
+		}
+		if req.LongURL == nil && req.Notify == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Aucune modification demandée ('long_url' ou 'notify')"})
+			return
+		}
+
+		link, err := linkService.UpdateLink(c.Param("shortCode"), services.LinkUpdate{
+			LongURL: req.LongURL,
+			Notify:  req.Notify,
+		}, callerKeyID(c))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Code court non trouvé"})
+				return
+			}
+			if errors.Is(err, urlpolicy.ErrRejected) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la mise à jour du lien"})
+			return
+		}
+
+		c.JSON(http.StatusOK, linkResponse(link))
+	}
+}
+
+// DeleteLinkHandler gère la suppression d'un lien (mise à la corbeille).
+func DeleteLinkHandler(linkService *services.LinkService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := linkService.DeleteLink(c.Param("shortCode"), callerKeyID(c)); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Code court non trouvé"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la suppression du lien"})
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// ListTrashHandler gère la liste des liens présents dans la corbeille.
+func ListTrashHandler(linkService *services.LinkService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		links, err := linkService.ListTrashedLinks(callerKeyID(c))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération de la corbeille"})
+			return
+		}
+
+		items := make([]gin.H, 0, len(links))
+		for i := range links {
+			items = append(items, linkResponse(&links[i]))
+		}
+
+		c.JSON(http.StatusOK, gin.H{"links": items})
+	}
+}
+
+// RestoreLinkHandler gère la restauration d'un lien depuis la corbeille.
+func RestoreLinkHandler(linkService *services.LinkService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		link, err := linkService.RestoreLink(c.Param("shortCode"), callerKeyID(c))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Lien introuvable dans la corbeille"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la restauration du lien"})
+			return
+		}
+
+		c.JSON(http.StatusOK, linkResponse(link))
+	}
+}
+
+// PurgeLinkHandler gère la suppression définitive d'un lien de la corbeille.
+func PurgeLinkHandler(linkService *services.LinkService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := linkService.PurgeLink(c.Param("shortCode"), callerKeyID(c)); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Lien introuvable dans la corbeille"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la purge du lien"})
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// PurgeTrashHandler gère la purge de la corbeille.
+// Le paramètre optionnel older_than (ex: "720h") limite la purge aux liens supprimés depuis plus longtemps.
+func PurgeTrashHandler(linkService *services.LinkService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var olderThan time.Duration
+		if raw := c.Query("older_than"); raw != "" {
+			d, err := time.ParseDuration(raw)
+			if err != nil || d < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Paramètre 'older_than' invalide (ex: \"720h\")"})
+				return
+			}
+			olderThan = d
+		}
+
+		purged, err := linkService.PurgeTrash(olderThan, callerKeyID(c))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la purge de la corbeille"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"purged": purged})
+	}
+}
+
+// RedirectHandler gère la redirection des URLs courtes vers leurs URLs longues.
+// Les événements de clic qui ne peuvent pas être placés dans le channel sont écrits dans le spool.
+// Si anonymizeIP est vrai (mode RGPD), l'IP est tronquée dès la création de l'événement :
+// l'adresse complète n'est ainsi jamais écrite sur disque, même dans le spool.
+func RedirectHandler(linkService *services.LinkService, clickSpool *spool.Spool, anonymizeIP bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			metrics.Redirects.WithLabelValues(strconv.Itoa(c.Writer.Status())).Inc()
+		}()
+
 		shortCode := c.Param("shortCode")
 		log.Printf("[DEBUG] Tentative de redirection pour le code court: %s", shortCode)
 
@@ -93,35 +438,74 @@ func RedirectHandler(linkService *services.LinkService) gin.HandlerFunc {
 			return
 		}
 
+		// Un lien expiré ne redirige plus et n'enregistre pas de clic
+		if link.IsExpired(time.Now()) {
+			c.JSON(http.StatusGone, gin.H{"error": "Ce lien a expiré"})
+			return
+		}
+
 		// Créer un événement de clic
+		clientIP := c.ClientIP()
+		if anonymizeIP {
+			clientIP = analytics.AnonymizeIP(clientIP)
+		}
 		clickEvent := models.ClickEvent{
 			LinkID:    link.ID,
 			UserAgent: c.Request.UserAgent(),
-			IPAddress: c.ClientIP(),
+			IPAddress: clientIP,
+			Referrer:  analytics.NormalizeReferrer(c.Request.Referer()),
+			IsBot:     analytics.IsBotRequest(c.Request),
 			Timestamp: time.Now(),
 		}
 
 		log.Printf("[DEBUG] Envoi d'un événement de clic pour le lien ID %d (code: %s)", link.ID, shortCode)
 
 		// Envoyer l'événement de clic au channel de manière non bloquante
-		select {
-		case ClickEventsChannel <- clickEvent:
+		if sendClickEvent(clickEvent) {
 			log.Printf("[DEBUG] Événement de clic envoyé avec succès pour le lien %s", shortCode)
-		default:
-			log.Printf("[WARN] Channel de clics plein, événement ignoré pour le lien %s", shortCode)
+		} else if clickSpool == nil {
+			metrics.ClickEventsDropped.Inc()
+			log.Printf("[WARN] Channel de clics plein ou fermé, événement ignoré pour le lien %s", shortCode)
+		} else if err := clickSpool.Append(clickEvent); err != nil {
+			metrics.ClickEventsDropped.Inc()
+			log.Printf("[WARN] Channel de clics plein et spool indisponible, événement ignoré pour le lien %s : %v", shortCode, err)
+		} else {
+			metrics.ClickEventsSpooled.Inc()
+			log.Printf("[DEBUG] Channel de clics plein, événement placé dans le spool pour le lien %s", shortCode)
 		}
 
 		c.Redirect(http.StatusFound, link.LongURL)
 	}
 }
 
-// GetLinkStatsHandler gère la récupération des statistiques d'un lien
-func GetLinkStatsHandler(linkService *services.LinkService) gin.HandlerFunc {
+// statsBreakdowns associe chaque répartition renvoyée par l'API de statistiques à sa dimension de clic.
+var statsBreakdowns = map[string]string{
+	"browsers":          repository.DimensionBrowser,
+	"operating_systems": repository.DimensionOS,
+	"devices":           repository.DimensionDevice,
+}
+
+// defaultTopBreakdown est le nombre de valeurs renvoyées par défaut dans les répartitions des statistiques.
+const defaultTopBreakdown = 10
+
+// GetLinkStatsHandler gère la récupération des statistiques d'un lien.
+// Le paramètre optionnel 'top' limite le nombre de valeurs des répartitions
+// (referrers, navigateurs, systèmes d'exploitation et appareils).
+// Les clics de robots sont exclus des totaux et répartitions, sauf avec include_bots=true.
+func GetLinkStatsHandler(linkService *services.LinkService, clickService *services.ClickService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		shortCode := c.Param("shortCode")
 		log.Printf("[DEBUG] Récupération des statistiques pour le code court: %s", shortCode)
 
-		link, totalClicks, err := linkService.GetLinkStats(shortCode)
+		top, err := strconv.Atoi(c.DefaultQuery("top", strconv.Itoa(defaultTopBreakdown)))
+		if err != nil || top < 1 || top > maxPageSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Paramètre 'top' invalide (1-%d)", maxPageSize)})
+			return
+		}
+
+		includeBots := c.Query("include_bots") == "true"
+
+		stats, err := linkService.GetLinkStats(shortCode, callerKeyID(c))
 		if err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
 				c.JSON(http.StatusNotFound, gin.H{"error": "Code court non trouvé"})
@@ -131,13 +515,211 @@ func GetLinkStatsHandler(linkService *services.LinkService) gin.HandlerFunc {
 			return
 		}
 
+		link := stats.Link
+		totalClicks := stats.TotalClicks(includeBots)
+
+		uniqueVisitors, err := clickService.GetUniqueVisitors(link.ID, includeBots)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération des statistiques"})
+			return
+		}
+
+		topReferrers, err := clickService.GetTopReferrers(link.ID, top, includeBots)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération des statistiques"})
+			return
+		}
+
+		breakdowns := make(map[string][]repository.DimensionCount, len(statsBreakdowns))
+		for key, dimension := range statsBreakdowns {
+			counts, err := clickService.GetBreakdown(link.ID, dimension, top, includeBots)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération des statistiques"})
+				return
+			}
+			breakdowns[key] = counts
+		}
+
 		log.Printf("[DEBUG] Statistiques récupérées pour %s : %d clics", shortCode, totalClicks)
 
+		c.JSON(http.StatusOK, gin.H{
+			"short_code":      link.ShortCode,
+			"long_url":        link.LongURL,
+			"total_clicks":    totalClicks,
+			"unique_visitors": uniqueVisitors,
+			"human_clicks":    stats.HumanClicks,
+			"bot_clicks":      stats.BotClicks,
+			"include_bots":    includeBots,
+			"created_at":      link.CreatedAt,
+			"expires_at":      link.ExpiresAt,
+			"top_referrers":   topReferrers,
+			"breakdowns":      breakdowns,
+		})
+	}
+}
+
+// defaultTimeSeriesRanges définit la période couverte par défaut selon la granularité demandée.
+var defaultTimeSeriesRanges = map[string]time.Duration{
+	services.IntervalHour: 24 * time.Hour,
+	services.IntervalDay:  30 * 24 * time.Hour,
+	services.IntervalWeek: 12 * 7 * 24 * time.Hour,
+}
+
+// parseTimeParam interprète un paramètre de date au format RFC 3339 ou "AAAA-MM-JJ"
+// (minuit dans le fuseau horaire loc).
+func parseTimeParam(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", value, loc)
+}
+
+// GetLinkTimeSeriesHandler gère la récupération du nombre de clics d'un lien par tranche de temps.
+// Paramètres de requête : from, to (RFC 3339 ou AAAA-MM-JJ), interval (hour|day|week), tz (ex: Europe/Paris)
+// et include_bots (true pour compter les clics de robots).
+func GetLinkTimeSeriesHandler(linkService *services.LinkService, clickService *services.ClickService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		interval := c.DefaultQuery("interval", services.IntervalDay)
+		defaultRange, ok := defaultTimeSeriesRanges[interval]
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Paramètre 'interval' invalide (hour|day|week)"})
+			return
+		}
+
+		loc, err := time.LoadLocation(c.DefaultQuery("tz", "UTC"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Paramètre 'tz' invalide (ex: Europe/Paris)"})
+			return
+		}
+
+		to := time.Now()
+		if raw := c.Query("to"); raw != "" {
+			if to, err = parseTimeParam(raw, loc); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Paramètre 'to' invalide (RFC 3339 ou AAAA-MM-JJ)"})
+				return
+			}
+		}
+		from := to.Add(-defaultRange)
+		if raw := c.Query("from"); raw != "" {
+			if from, err = parseTimeParam(raw, loc); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Paramètre 'from' invalide (RFC 3339 ou AAAA-MM-JJ)"})
+				return
+			}
+		}
+
+		link, err := linkService.GetOwnedLink(c.Param("shortCode"), callerKeyID(c))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Code court non trouvé"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération du lien"})
+			return
+		}
+
+		points, err := clickService.GetClickTimeSeries(link.ID, from, to, interval, loc, c.Query("include_bots") == "true")
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidTimeSeries) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération des statistiques"})
+			return
+		}
+
+		total := 0
+		for _, p := range points {
+			total += p.Clicks
+		}
+
 		c.JSON(http.StatusOK, gin.H{
 			"short_code":   link.ShortCode,
-			"long_url":     link.LongURL,
-			"total_clicks": totalClicks,
-			"created_at":   link.CreatedAt,
+			"interval":     interval,
+			"timezone":     loc.String(),
+			"from":         from.In(loc),
+			"to":           to.In(loc),
+			"total_clicks": total,
+			"points":       points,
+		})
+	}
+}
+
+// Paramètres par défaut de l'API de santé d'un lien.
+const (
+	defaultHealthWindow = 7 * 24 * time.Hour
+	defaultHealthChecks = 20
+	maxHealthChecks     = 100
+)
+
+// GetLinkHealthHandler gère la récupération de l'état de la destination d'un lien surveillée par le moniteur.
+// Paramètres de requête : window (période de calcul de la disponibilité, ex: "24h", 7 jours par défaut)
+// et limit (nombre de vérifications récentes renvoyées).
+func GetLinkHealthHandler(linkService *services.LinkService, linkHealthService *services.LinkHealthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		window := defaultHealthWindow
+		if raw := c.Query("window"); raw != "" {
+			d, err := time.ParseDuration(raw)
+			if err != nil || d <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Paramètre 'window' invalide (ex: \"24h\", \"720h\")"})
+				return
+			}
+			window = d
+		}
+
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultHealthChecks)))
+		if err != nil || limit < 1 || limit > maxHealthChecks {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Paramètre 'limit' invalide (1-%d)", maxHealthChecks)})
+			return
+		}
+
+		link, err := linkService.GetOwnedLink(c.Param("shortCode"), callerKeyID(c))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Code court non trouvé"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération du lien"})
+			return
+		}
+
+		health, err := linkHealthService.GetLinkHealth(link, window, limit, time.Now())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération de l'état du lien"})
+			return
+		}
+
+		checks := make([]gin.H, 0, len(health.RecentChecks))
+		for _, check := range health.RecentChecks {
+			redirects := check.RedirectChain
+			if redirects == nil {
+				redirects = []models.RedirectHop{}
+			}
+			checks = append(checks, gin.H{
+				"checked_at":          check.CheckedAt,
+				"up":                  check.Up,
+				"result":              check.Result,
+				"method":              check.Method,
+				"status_code":         check.StatusCode,
+				"final_url":           check.FinalURL,
+				"redirect_chain":      redirects,
+				"cert_expires_at":     check.CertExpiresAt,
+				"content_hash":        check.ContentHash,
+				"content_fingerprint": check.ContentFingerprint,
+				"latency_ms":          check.LatencyMs,
+				"error":               check.Error,
+			})
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"short_code":       link.ShortCode,
+			"long_url":         link.LongURL,
+			"state":            health.State,
+			"last_result":      health.LastResult,
+			"last_checked_at":  health.LastCheckedAt,
+			"window":           health.Window.String(),
+			"checks_in_window": health.WindowChecks,
+			"uptime_percent":   health.UptimePercent,
+			"recent_checks":    checks,
 		})
 	}
 }
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/config"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/migrations"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/axellelanca/urlshortener/internal/spool"
+	"github.com/gin-gonic/gin"
+)
+
+// newTestLinkService ouvre une base SQLite en mémoire migrée contenant le lien "abc123".
+func newTestLinkService(t *testing.T) *services.LinkService {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Database.Driver = database.DriverSQLite
+	cfg.Database.DSN = ":memory:"
+	cfg.Database.MaxOpenConns = 1
+
+	db, err := database.Open(cfg)
+	if err != nil {
+		t.Fatalf("ouverture de la base: %v", err)
+	}
+	t.Cleanup(func() { database.Close(db) })
+
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		t.Fatalf("initialisation des migrations: %v", err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("application des migrations: %v", err)
+	}
+
+	linkRepo := repository.NewLinkRepository(db)
+	if err := linkRepo.CreateLink(&models.Link{ShortCode: "abc123", LongURL: "https://example.com", CreatedAt: time.Now()}); err != nil {
+		t.Fatalf("création du lien: %v", err)
+	}
+	return services.NewLinkService(linkRepo, nil)
+}
+
+func TestRedirectSpoolsAnonymizedIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	linkService := newTestLinkService(t)
+
+	for _, tt := range []struct {
+		name        string
+		anonymizeIP bool
+		wantIP      string
+	}{
+		{"mode RGPD", true, "192.0.2.0"},
+		{"sans anonymisation", false, "192.0.2.42"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			clickSpool, err := spool.Open(t.TempDir(), 1<<20)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Channel sans buffer et sans worker : l'événement est forcément placé dans le spool
+			router := gin.New()
+			SetupRoutes(router, linkService, nil, nil, RouteOptions{ClickSpool: clickSpool, AnonymizeIP: tt.anonymizeIP})
+			t.Cleanup(CloseClickEventsChannel)
+
+			req := httptest.NewRequest(http.MethodGet, "/abc123", nil)
+			req.RemoteAddr = "192.0.2.42:54321"
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != http.StatusFound {
+				t.Fatalf("statut %d, 302 attendu", rec.Code)
+			}
+
+			if err := clickSpool.Close(); err != nil {
+				t.Fatal(err)
+			}
+			segments, err := clickSpool.SealedSegments()
+			if err != nil || len(segments) != 1 {
+				t.Fatalf("%d segment(s) dans le spool, 1 attendu (%v)", len(segments), err)
+			}
+			events, err := spool.ReadSegment(segments[0])
+			if err != nil || len(events) != 1 {
+				t.Fatalf("%d événement(s) dans le spool, 1 attendu (%v)", len(events), err)
+			}
+			if events[0].IPAddress != tt.wantIP {
+				t.Errorf("IP '%s' écrite dans le spool, '%s' attendue", events[0].IPAddress, tt.wantIP)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/analytics"
+	"github.com/axellelanca/urlshortener/internal/metrics"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/ratelimit"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// AccessLogger journalise chaque requête au format du logger par défaut de Gin.
+// Si anonymizeIP est vrai (mode RGPD), l'adresse IP du client est tronquée comme celle des clics enregistrés.
+func AccessLogger(anonymizeIP bool) gin.HandlerFunc {
+	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
+		if anonymizeIP {
+			param.ClientIP = analytics.AnonymizeIP(param.ClientIP)
+		}
+
+		var statusColor, methodColor, resetColor string
+		if param.IsOutputColor() {
+			statusColor = param.StatusCodeColor()
+			methodColor = param.MethodColor()
+			resetColor = param.ResetColor()
+		}
+		if param.Latency > time.Minute {
+			param.Latency = param.Latency.Truncate(time.Second)
+		}
+		return fmt.Sprintf("[GIN] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v\n%s",
+			param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+			statusColor, param.StatusCode, resetColor,
+			param.Latency,
+			param.ClientIP,
+			methodColor, param.Method, resetColor,
+			param.Path,
+			param.ErrorMessage,
+		)
+	})
+}
+
+// apiKeyContextKey est la clé sous laquelle la clé d'API authentifiée est stockée dans le contexte Gin.
+const apiKeyContextKey = "apiKey"
+
+// APIKeyAuthMiddleware protège les routes en exigeant une clé d'API valide.
+// La clé est lue dans l'en-tête "Authorization: Bearer <clé>" ou, à défaut, dans "X-API-Key".
+func APIKeyAuthMiddleware(apiKeyService *services.APIKeyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		secret := extractAPIKey(c.Request)
+
+		key, err := apiKeyService.Authenticate(secret)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidAPIKey) {
+				c.Header("WWW-Authenticate", `Bearer realm="api"`)
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Clé d'API manquante ou invalide"})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la vérification de la clé d'API"})
+			return
+		}
+
+		c.Set(apiKeyContextKey, key)
+		c.Next()
+	}
+}
+
+// extractAPIKey lit la clé d'API fournie par le client dans les en-têtes de la requête.
+func extractAPIKey(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		if token, ok := strings.CutPrefix(auth, "Bearer "); ok {
+			return strings.TrimSpace(token)
+		}
+	}
+	return strings.TrimSpace(r.Header.Get("X-API-Key"))
+}
+
+// callerKeyID renvoie l'ID de la clé d'API ayant authentifié la requête,
+// ou nil si l'authentification est désactivée.
+func callerKeyID(c *gin.Context) *uint {
+	value, ok := c.Get(apiKeyContextKey)
+	if !ok {
+		return nil
+	}
+	key, ok := value.(*models.APIKey)
+	if !ok {
+		return nil
+	}
+	return &key.ID
+}
+
+// RateLimitMiddleware limite le débit des requêtes de chaque client à l'aide d'un seau de jetons.
+// Le client est identifié par sa clé d'API si la requête est authentifiée, sinon par son adresse IP :
+// le middleware doit donc être placé après APIKeyAuthMiddleware. 'route' nomme le groupe de routes
+// dans les métriques. Les en-têtes RateLimit-Limit, RateLimit-Remaining et RateLimit-Reset sont
+// renvoyés sur chaque réponse, et Retry-After accompagne les réponses 429.
+func RateLimitMiddleware(limiter *ratelimit.Limiter, route string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := "ip:" + c.ClientIP()
+		if keyID := callerKeyID(c); keyID != nil {
+			key = "key:" + strconv.FormatUint(uint64(*keyID), 10)
+		}
+
+		result := limiter.Allow(key)
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+
+		if !result.Allowed {
+			metrics.RateLimited.WithLabelValues(route).Inc()
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Trop de requêtes, réessayez plus tard"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// ceilSeconds arrondit une durée à la seconde supérieure, comme l'attendent les en-têtes HTTP.
+func ceilSeconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,138 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Stats regroupe les compteurs d'utilisation d'un cache.
+type Stats struct {
+	Hits      uint64 // Lectures servies par le cache
+	Misses    uint64 // Lectures absentes ou expirées
+	Evictions uint64 // Entrées retirées pour libérer de la place
+	Size      int    // Nombre d'entrées actuellement en cache
+}
+
+// entry est un élément de la liste LRU.
+type entry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+// LRU est un cache borné, sûr en accès concurrent, qui évince l'entrée la moins récemment utilisée
+// lorsque sa capacité est atteinte. Chaque entrée a sa propre durée de vie.
+type LRU[K comparable, V any] struct {
+	capacity int
+
+	mu    sync.Mutex
+	order *list.List          // Entrées de la plus récemment utilisée (devant) à la plus ancienne (derrière)
+	items map[K]*list.Element // Index des entrées par clé
+	stats Stats
+	now   func() time.Time
+}
+
+// NewLRU crée un cache pouvant contenir au plus capacity entrées.
+func NewLRU[K comparable, V any](capacity int) *LRU[K, V] {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &LRU[K, V]{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[K]*list.Element, capacity),
+		now:      time.Now,
+	}
+}
+
+// Get renvoie la valeur associée à key si elle est présente et non expirée.
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		e := elem.Value.(*entry[K, V])
+		if c.now().Before(e.expiresAt) {
+			c.order.MoveToFront(elem)
+			c.stats.Hits++
+			return e.value, true
+		}
+		c.removeElement(elem)
+	}
+
+	c.stats.Misses++
+	var zero V
+	return zero, false
+}
+
+// Set ajoute ou remplace la valeur associée à key pour la durée ttl.
+func (c *LRU[K, V]) Set(key K, value V, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := c.now().Add(ttl)
+	if elem, ok := c.items[key]; ok {
+		e := elem.Value.(*entry[K, V])
+		e.value = value
+		e.expiresAt = expiresAt
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&entry[K, V]{key: key, value: value, expiresAt: expiresAt})
+	if c.order.Len() > c.capacity {
+		c.removeElement(c.order.Back())
+		c.stats.Evictions++
+	}
+}
+
+// Delete retire l'entrée associée à key.
+func (c *LRU[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		c.removeElement(elem)
+	}
+}
+
+// DeleteFunc retire toutes les entrées pour lesquelles match renvoie true.
+func (c *LRU[K, V]) DeleteFunc(match func(key K, value V) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for elem := c.order.Front(); elem != nil; {
+		next := elem.Next()
+		e := elem.Value.(*entry[K, V])
+		if match(e.key, e.value) {
+			c.removeElement(elem)
+		}
+		elem = next
+	}
+}
+
+// Purge vide entièrement le cache.
+func (c *LRU[K, V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	c.items = make(map[K]*list.Element, c.capacity)
+}
+
+// Stats renvoie les compteurs d'utilisation du cache.
+func (c *LRU[K, V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Size = c.order.Len()
+	return stats
+}
+
+// removeElement retire un élément de la liste et de l'index. Le verrou doit être détenu.
+func (c *LRU[K, V]) removeElement(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.items, elem.Value.(*entry[K, V]).key)
+}
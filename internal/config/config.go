@@ -11,22 +11,111 @@ import (
 // (ou des variables d'environnement) aux champs de la structure Go.
 type Config struct {
 	Server struct {
-		Port    int    `mapstructure:"port"`
-		BaseURL string `mapstructure:"base_url"`
+		Port                   int      `mapstructure:"port"`
+		BaseURL                string   `mapstructure:"base_url"`
+		ShutdownTimeoutSeconds int      `mapstructure:"shutdown_timeout_seconds"` // Délai accordé aux requêtes en cours lors de l'arrêt
+		DrainTimeoutSeconds    int      `mapstructure:"drain_timeout_seconds"`    // Délai accordé aux workers pour enregistrer les clics restants
+		TrustedProxies         []string `mapstructure:"trusted_proxies"`          // Proxys dont les en-têtes X-Forwarded-For sont acceptés
 	} `mapstructure:"server"`
 
 	Database struct {
-		Name string `mapstructure:"name"`
+		Driver                 string `mapstructure:"driver"`                    // "sqlite", "postgres" ou "mysql"
+		Name                   string `mapstructure:"name"`                      // Fichier de la base SQLite
+		DSN                    string `mapstructure:"dsn"`                       // Chaîne de connexion (requise pour postgres et mysql)
+		MaxOpenConns           int    `mapstructure:"max_open_conns"`            // Connexions ouvertes maximum (0 = illimité)
+		MaxIdleConns           int    `mapstructure:"max_idle_conns"`            // Connexions inactives conservées
+		ConnMaxLifetimeMinutes int    `mapstructure:"conn_max_lifetime_minutes"` // Durée de vie maximale d'une connexion (0 = illimitée)
 	} `mapstructure:"database"`
 
 	Analytics struct {
-		BufferSize  int `mapstructure:"buffer_size"`
-		WorkerCount int `mapstructure:"worker_count"` // Nombre de goroutines pour traiter les clics
+		BufferSize         int    `mapstructure:"buffer_size"`
+		WorkerCount        int    `mapstructure:"worker_count"`                  // Nombre de goroutines pour traiter les clics
+		BatchSize          int    `mapstructure:"batch_size"`                    // Nombre de clics écrits en base par lot
+		FlushInterval      int    `mapstructure:"flush_interval_ms"`             // Délai maximum (ms) avant l'écriture d'un lot incomplet
+		SpoolDir           string `mapstructure:"spool_dir"`                     // Répertoire du spool des clics en attente (vide = désactivé)
+		SpoolSegmentKB     int    `mapstructure:"spool_segment_kb"`              // Taille maximale d'un segment du spool
+		SpoolReplaySeconds int    `mapstructure:"spool_replay_interval_seconds"` // Intervalle de rejeu du spool
+		VisitorSecret      string `mapstructure:"visitor_secret"`                // Secret servant à dériver le sel quotidien des empreintes de visiteurs
 	} `mapstructure:"analytics"`
 
 	Monitor struct {
-		IntervalMinutes int `mapstructure:"interval_minutes"`
+		IntervalMinutes    int `mapstructure:"interval_minutes"`
+		HistoryDays        int `mapstructure:"history_days"`             // Durée de conservation de l'historique des vérifications (0 = illimitée)
+		Workers            int `mapstructure:"workers"`                  // Nombre de vérifications simultanées
+		PerHostConcurrency int `mapstructure:"per_host_concurrency"`     // Vérifications simultanées maximum vers un même hôte
+		MaxRedirects       int `mapstructure:"max_redirects"`            // Redirections suivies au maximum par vérification
+		CertExpiryDays     int `mapstructure:"cert_expiry_warning_days"` // Alerte quand un certificat TLS expire dans moins de N jours (0 = désactivée)
+		ContentMaxKB       int `mapstructure:"content_max_kb"`           // Taille lue des pages pour leur empreinte (0 = analyse du contenu désactivée)
+		ContentChangeBits  int `mapstructure:"content_change_threshold"` // Bits d'empreinte différents (sur 64) signalant un changement de contenu
 	} `mapstructure:"monitor"`
+
+	Notifications struct {
+		Webhook struct {
+			URL            string `mapstructure:"url"`             // Destination des événements (vide = désactivé)
+			Secret         string `mapstructure:"secret"`          // Secret partagé de la signature HMAC-SHA256
+			MaxRetries     int    `mapstructure:"max_retries"`     // Nouvelles tentatives en cas d'échec temporaire
+			TimeoutSeconds int    `mapstructure:"timeout_seconds"` // Durée maximale d'une tentative
+		} `mapstructure:"webhook"`
+		SMTP struct {
+			Host     string   `mapstructure:"host"` // Serveur SMTP (vide = désactivé)
+			Port     int      `mapstructure:"port"`
+			Username string   `mapstructure:"username"` // Vide = sans authentification
+			Password string   `mapstructure:"password"`
+			From     string   `mapstructure:"from"`
+			To       []string `mapstructure:"to"`
+		} `mapstructure:"smtp"`
+		Command struct {
+			Path           string   `mapstructure:"path"` // Commande exécutée pour chaque événement (vide = désactivé)
+			Args           []string `mapstructure:"args"`
+			TimeoutSeconds int      `mapstructure:"timeout_seconds"`
+		} `mapstructure:"command"`
+	} `mapstructure:"notifications"`
+
+	Auth struct {
+		Enabled bool `mapstructure:"enabled"` // Exige une clé d'API sur les routes /api/v1
+	} `mapstructure:"auth"`
+
+	URLPolicy struct {
+		AllowedSchemes       []string `mapstructure:"allowed_schemes"`        // Schémas acceptés pour les URLs de destination
+		DomainAllowlistFile  string   `mapstructure:"domain_allowlist_file"`  // Seuls ces domaines sont acceptés (vide = tous)
+		DomainBlocklistFile  string   `mapstructure:"domain_blocklist_file"`  // Domaines refusés (vide = aucun)
+		AllowPrivateNetworks bool     `mapstructure:"allow_private_networks"` // Autorise les destinations privées ou locales
+	} `mapstructure:"url_policy"`
+
+	Cache struct {
+		Enabled            bool `mapstructure:"enabled"`              // Cache des liens devant la base (redirections)
+		Size               int  `mapstructure:"size"`                 // Nombre maximum de codes courts en cache
+		TTLSeconds         int  `mapstructure:"ttl_seconds"`          // Durée de vie d'un lien en cache
+		NegativeTTLSeconds int  `mapstructure:"negative_ttl_seconds"` // Durée de vie d'un code inconnu en cache
+	} `mapstructure:"cache"`
+
+	RateLimit struct {
+		Enabled  bool            `mapstructure:"enabled"`  // Limite le débit des requêtes par clé d'API ou adresse IP
+		Create   RateLimitConfig `mapstructure:"create"`   // POST /api/v1/links
+		Redirect RateLimitConfig `mapstructure:"redirect"` // GET/HEAD /:shortCode
+	} `mapstructure:"rate_limit"`
+
+	Metrics struct {
+		Enabled bool `mapstructure:"enabled"` // Expose les métriques Prometheus sur /metrics
+	} `mapstructure:"metrics"`
+
+	Privacy struct {
+		AnonymizeIP            bool   `mapstructure:"anonymize_ip"`             // Tronque les IP (/24 en IPv4, /48 en IPv6) avant enregistrement
+		RetentionDays          int    `mapstructure:"retention_days"`           // Durée de conservation des clics (0 = illimitée)
+		RetentionMode          string `mapstructure:"retention_mode"`           // "delete" ou "anonymize"
+		RetentionIntervalHours int    `mapstructure:"retention_interval_hours"` // Fréquence d'application de la rétention
+	} `mapstructure:"privacy"`
+
+	Expiration struct {
+		SweepIntervalMinutes int `mapstructure:"sweep_interval_minutes"` // Fréquence du nettoyage des liens expirés
+		PurgeAfterHours      int `mapstructure:"purge_after_hours"`      // Délai avant mise à la corbeille d'un lien expiré
+	} `mapstructure:"expiration"`
+}
+
+// RateLimitConfig décrit le seau de jetons appliqué à un groupe de routes.
+type RateLimitConfig struct {
+	RequestsPerMinute int `mapstructure:"requests_per_minute"` // Débit soutenu autorisé par client
+	Burst             int `mapstructure:"burst"`               // Nombre de requêtes autorisées en rafale
 }
 
 // LoadConfig charge la configuration de l'application en utilisant Viper.
@@ -49,10 +138,59 @@ func LoadConfig() (*Config, error) {
 	// ou si le fichier n'existe pas.
 	viper.SetDefault("server.port", 8080)
 	viper.SetDefault("server.base_url", "http://localhost:8080")
+	viper.SetDefault("server.shutdown_timeout_seconds", 15)
+	viper.SetDefault("server.drain_timeout_seconds", 30)
+	viper.SetDefault("database.driver", "sqlite")
 	viper.SetDefault("database.name", "url_shortener.db")
+	viper.SetDefault("database.max_open_conns", 0)
+	viper.SetDefault("database.max_idle_conns", 2)
+	viper.SetDefault("database.conn_max_lifetime_minutes", 0)
+
+	// Le DSN peut contenir un mot de passe : il peut être fourni par variable d'environnement plutôt que dans le fichier
+	viper.BindEnv("database.dsn", "URLSHORTENER_DATABASE_DSN")
 	viper.SetDefault("analytics.buffer_size", 1000)
 	viper.SetDefault("analytics.worker_count", 5) // Valeur par défaut pour le nombre de workers
+	viper.SetDefault("analytics.batch_size", 100)
+	viper.SetDefault("analytics.flush_interval_ms", 1000)
+	viper.SetDefault("analytics.spool_dir", "click_spool")
+	viper.SetDefault("analytics.spool_segment_kb", 1024)
+	viper.SetDefault("analytics.spool_replay_interval_seconds", 10)
 	viper.SetDefault("monitor.interval_minutes", 5)
+	viper.SetDefault("monitor.history_days", 30)
+	viper.SetDefault("monitor.workers", 10)
+	viper.SetDefault("monitor.per_host_concurrency", 2)
+	viper.SetDefault("monitor.max_redirects", 5)
+	viper.SetDefault("monitor.cert_expiry_warning_days", 14)
+	viper.SetDefault("monitor.content_max_kb", 0) // Analyse du contenu sur demande : elle lit le corps des pages
+	viper.SetDefault("monitor.content_change_threshold", 12)
+	viper.SetDefault("notifications.webhook.max_retries", 3)
+	viper.SetDefault("notifications.webhook.timeout_seconds", 10)
+	viper.SetDefault("notifications.smtp.port", 587)
+	viper.SetDefault("notifications.command.timeout_seconds", 10)
+
+	// Secrets des notifications, comme le DSN : fournis de préférence par variables d'environnement
+	viper.BindEnv("analytics.visitor_secret", "URLSHORTENER_VISITOR_SECRET")
+	viper.BindEnv("notifications.webhook.secret", "URLSHORTENER_WEBHOOK_SECRET")
+	viper.BindEnv("notifications.smtp.password", "URLSHORTENER_SMTP_PASSWORD")
+	viper.SetDefault("auth.enabled", true)
+	viper.SetDefault("url_policy.allowed_schemes", []string{"http", "https"})
+	viper.SetDefault("url_policy.allow_private_networks", false)
+	viper.SetDefault("rate_limit.enabled", true)
+	viper.SetDefault("rate_limit.create.requests_per_minute", 30)
+	viper.SetDefault("rate_limit.create.burst", 10)
+	viper.SetDefault("rate_limit.redirect.requests_per_minute", 600)
+	viper.SetDefault("rate_limit.redirect.burst", 100)
+	viper.SetDefault("metrics.enabled", true)
+	viper.SetDefault("cache.enabled", true)
+	viper.SetDefault("cache.size", 10000)
+	viper.SetDefault("cache.ttl_seconds", 300)
+	viper.SetDefault("cache.negative_ttl_seconds", 30)
+	viper.SetDefault("privacy.anonymize_ip", false)
+	viper.SetDefault("privacy.retention_days", 0)
+	viper.SetDefault("privacy.retention_mode", "delete")
+	viper.SetDefault("privacy.retention_interval_hours", 24)
+	viper.SetDefault("expiration.sweep_interval_minutes", 10)
+	viper.SetDefault("expiration.purge_after_hours", 168)
 
 	// Lire le fichier de configuration.
 	if err := viper.ReadInConfig(); err != nil {
@@ -69,8 +207,8 @@ func LoadConfig() (*Config, error) {
 	}
 
 	// Log pour vérifier la config chargée
-	log.Printf("Configuration loaded: Server Port=%d, DB Name=%s, Analytics Buffer=%d, Workers=%d, Monitor Interval=%dmin",
-		cfg.Server.Port, cfg.Database.Name, cfg.Analytics.BufferSize, cfg.Analytics.WorkerCount, cfg.Monitor.IntervalMinutes)
+	log.Printf("Configuration loaded: Server Port=%d, DB Driver=%s, DB Name=%s, Analytics Buffer=%d, Workers=%d, Monitor Interval=%dmin",
+		cfg.Server.Port, cfg.Database.Driver, cfg.Database.Name, cfg.Analytics.BufferSize, cfg.Analytics.WorkerCount, cfg.Monitor.IntervalMinutes)
 
 	return &cfg, nil // Retourne la configuration chargée
 }
@@ -0,0 +1,95 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/config"
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// Moteurs de base de données pris en charge (clé database.driver de la configuration).
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+	DriverMySQL    = "mysql"
+)
+
+// Open ouvre la connexion à la base de données décrite par la configuration et applique
+// les réglages du pool de connexions. Toutes les commandes passent par cette fonction.
+func Open(cfg *config.Config) (*gorm.DB, error) {
+	dialector, err := dialectorFor(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// TranslateError convertit les erreurs propres à chaque moteur (ex: violation d'unicité)
+	// en erreurs GORM communes comme gorm.ErrDuplicatedKey.
+	db, err := gorm.Open(dialector, &gorm.Config{TranslateError: true})
+	if err != nil {
+		return nil, fmt.Errorf("moteur %s: %w", cfg.Database.Driver, err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("échec de l'obtention de la base de données SQL sous-jacente: %w", err)
+	}
+	if cfg.Database.MaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+	}
+	if cfg.Database.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+	}
+	if cfg.Database.ConnMaxLifetimeMinutes > 0 {
+		sqlDB.SetConnMaxLifetime(time.Duration(cfg.Database.ConnMaxLifetimeMinutes) * time.Minute)
+	}
+
+	return db, nil
+}
+
+// Close ferme la connexion sous-jacente à la base de données.
+func Close(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("échec de l'obtention de la base de données SQL sous-jacente: %w", err)
+	}
+	return sqlDB.Close()
+}
+
+// dialectorFor renvoie le dialecte GORM correspondant au moteur configuré.
+// Pour SQLite, le DSN est facultatif : le nom du fichier (database.name) est utilisé par défaut.
+func dialectorFor(cfg *config.Config) (gorm.Dialector, error) {
+	dsn := cfg.Database.DSN
+
+	switch cfg.Database.Driver {
+	case DriverSQLite, "":
+		if dsn == "" {
+			dsn = cfg.Database.Name
+		}
+		return sqlite.Open(dsn), nil
+	case DriverPostgres:
+		if dsn == "" {
+			return nil, fmt.Errorf("database.dsn est requis pour le moteur %s", DriverPostgres)
+		}
+		return postgres.Open(dsn), nil
+	case DriverMySQL:
+		if dsn == "" {
+			return nil, fmt.Errorf("database.dsn est requis pour le moteur %s", DriverMySQL)
+		}
+		// Les horodatages doivent être lus en time.Time et stockés en UTC, comme avec les autres moteurs
+		mysqlCfg, err := mysqldriver.ParseDSN(dsn)
+		if err != nil {
+			return nil, fmt.Errorf("database.dsn invalide pour le moteur %s: %w", DriverMySQL, err)
+		}
+		mysqlCfg.ParseTime = true
+		mysqlCfg.Loc = time.UTC
+		return mysql.Open(mysqlCfg.FormatDSN()), nil
+	default:
+		return nil, fmt.Errorf("moteur de base de données inconnu: '%s' (%s|%s|%s)",
+			cfg.Database.Driver, DriverSQLite, DriverPostgres, DriverMySQL)
+	}
+}
@@ -0,0 +1,122 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/config"
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+)
+
+// testConfig renvoie une configuration limitée à la section database.
+func testConfig(driver, name, dsn string) *config.Config {
+	cfg := &config.Config{}
+	cfg.Database.Driver = driver
+	cfg.Database.Name = name
+	cfg.Database.DSN = dsn
+	return cfg
+}
+
+func TestDialectorForSQLite(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    *config.Config
+		wanted string
+	}{
+		{"fichier par défaut", testConfig(DriverSQLite, "url_shortener.db", ""), "url_shortener.db"},
+		{"moteur non renseigné", testConfig("", "url_shortener.db", ""), "url_shortener.db"},
+		{"DSN prioritaire", testConfig(DriverSQLite, "url_shortener.db", "file:test.db?_busy_timeout=5000"), "file:test.db?_busy_timeout=5000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dialector, err := dialectorFor(tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sqliteDialector, ok := dialector.(*sqlite.Dialector)
+			if !ok {
+				t.Fatalf("dialecte %T, SQLite attendu", dialector)
+			}
+			if sqliteDialector.DSN != tt.wanted {
+				t.Errorf("DSN '%s', '%s' attendu", sqliteDialector.DSN, tt.wanted)
+			}
+		})
+	}
+}
+
+func TestDialectorForPostgres(t *testing.T) {
+	dsn := "host=localhost user=urlshortener dbname=urlshortener sslmode=disable TimeZone=UTC"
+	dialector, err := dialectorFor(testConfig(DriverPostgres, "", dsn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	postgresDialector, ok := dialector.(*postgres.Dialector)
+	if !ok {
+		t.Fatalf("dialecte %T, PostgreSQL attendu", dialector)
+	}
+	if postgresDialector.DSN != dsn {
+		t.Errorf("DSN '%s', '%s' attendu", postgresDialector.DSN, dsn)
+	}
+}
+
+func TestDialectorForMySQLForcesParseTimeAndUTC(t *testing.T) {
+	tests := []struct {
+		name    string
+		dsn     string
+		charset string // Option conservée telle quelle
+	}{
+		{"sans options", "urlshortener:secret@tcp(localhost:3306)/urlshortener", ""},
+		{"options contraires", "urlshortener:secret@tcp(localhost:3306)/urlshortener?parseTime=false&loc=Local&charset=utf8mb4", "utf8mb4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dialector, err := dialectorFor(testConfig(DriverMySQL, "", tt.dsn))
+			if err != nil {
+				t.Fatal(err)
+			}
+			mysqlDialector, ok := dialector.(*mysql.Dialector)
+			if !ok {
+				t.Fatalf("dialecte %T, MySQL attendu", dialector)
+			}
+
+			parsed, err := mysqldriver.ParseDSN(mysqlDialector.DSN)
+			if err != nil {
+				t.Fatalf("DSN généré illisible '%s': %v", mysqlDialector.DSN, err)
+			}
+			if !parsed.ParseTime {
+				t.Errorf("parseTime non forcé: %s", mysqlDialector.DSN)
+			}
+			if parsed.Loc != time.UTC {
+				t.Errorf("fuseau %v, UTC attendu: %s", parsed.Loc, mysqlDialector.DSN)
+			}
+			if parsed.User != "urlshortener" || parsed.Passwd != "secret" || parsed.Addr != "localhost:3306" || parsed.DBName != "urlshortener" {
+				t.Errorf("paramètres de connexion perdus: %s", mysqlDialector.DSN)
+			}
+			if parsed.Params["charset"] != tt.charset {
+				t.Errorf("option charset '%s', '%s' attendue", parsed.Params["charset"], tt.charset)
+			}
+		})
+	}
+}
+
+func TestDialectorForErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *config.Config
+	}{
+		{"DSN PostgreSQL manquant", testConfig(DriverPostgres, "url_shortener.db", "")},
+		{"DSN MySQL manquant", testConfig(DriverMySQL, "url_shortener.db", "")},
+		{"DSN MySQL invalide", testConfig(DriverMySQL, "", "pas un dsn")},
+		{"moteur inconnu", testConfig("oracle", "", "dsn")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := dialectorFor(tt.cfg); err == nil {
+				t.Error("configuration invalide acceptée")
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package metrics
+
+import (
+	"github.com/axellelanca/urlshortener/internal/cache"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// namespace préfixe toutes les métriques exposées par le service.
+const namespace = "urlshortener"
+
+// Métriques du serveur HTTP et du pipeline de clics.
+var (
+	// Redirects compte les requêtes de redirection par code de statut HTTP renvoyé.
+	Redirects = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "redirects_total",
+		Help:      "Nombre de requêtes de redirection, par code de statut HTTP.",
+	}, []string{"status"})
+
+	// LinksCreated compte les liens créés via l'API.
+	LinksCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "links_created_total",
+		Help:      "Nombre de liens courts créés via l'API.",
+	})
+
+	// RateLimited compte les requêtes refusées par la limitation de débit, par groupe de routes.
+	RateLimited = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limited_total",
+		Help:      "Nombre de requêtes refusées (429) par la limitation de débit, par groupe de routes.",
+	}, []string{"route"})
+
+	// ClickEventsSpooled compte les événements de clic écrits dans le spool faute de place dans le channel.
+	ClickEventsSpooled = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "click_events_spooled_total",
+		Help:      "Nombre d'événements de clic écrits dans le spool disque.",
+	})
+
+	// ClickEventsDropped compte les événements de clic perdus (channel plein et spool absent ou en erreur).
+	ClickEventsDropped = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "click_events_dropped_total",
+		Help:      "Nombre d'événements de clic perdus.",
+	})
+
+	// ClicksInserted compte les clics enregistrés en base par les workers et le rejeu du spool.
+	ClicksInserted = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "clicks_inserted_total",
+		Help:      "Nombre de clics enregistrés en base.",
+	})
+
+	// ClickInsertErrors compte les échecs d'enregistrement de clics en base.
+	ClickInsertErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "click_insert_errors_total",
+		Help:      "Nombre d'erreurs lors de l'enregistrement des clics en base.",
+	})
+
+	// ClickInsertDuration mesure la durée d'enregistrement d'un lot de clics.
+	ClickInsertDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "click_insert_duration_seconds",
+		Help:      "Durée d'enregistrement d'un lot de clics en base.",
+		Buckets:   prometheus.DefBuckets,
+	})
+)
+
+// Métriques du moniteur d'URLs.
+var (
+	// MonitorChecks compte les vérifications d'URL effectuées, par résultat (up ou down).
+	MonitorChecks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "monitor",
+		Name:      "checks_total",
+		Help:      "Nombre de vérifications d'URL effectuées, par résultat.",
+	}, []string{"result"})
+
+	// MonitorCheckResults compte les vérifications d'URL par classification (ok, redirect, client_error, dns...).
+	MonitorCheckResults = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "monitor",
+		Name:      "check_results_total",
+		Help:      "Nombre de vérifications d'URL effectuées, par classification du résultat.",
+	}, []string{"result"})
+
+	// MonitorLinks indique le nombre de liens accessibles (up) et inaccessibles (down) lors du dernier cycle.
+	MonitorLinks = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "monitor",
+		Name:      "links",
+		Help:      "Nombre de liens par état lors du dernier cycle de vérification.",
+	}, []string{"state"})
+
+	// Notifications compte les événements du moniteur transmis aux notificateurs, par canal et résultat.
+	Notifications = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "monitor",
+		Name:      "notifications_total",
+		Help:      "Nombre de notifications envoyées par le moniteur, par canal et résultat.",
+	}, []string{"notifier", "result"})
+
+	// MonitorCyclesSkipped compte les cycles de vérification ignorés car le précédent n'était pas terminé.
+	MonitorCyclesSkipped = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "monitor",
+		Name:      "cycles_skipped_total",
+		Help:      "Nombre de cycles de vérification ignorés car le précédent était toujours en cours.",
+	})
+
+	// MonitorCycleDuration indique la durée du dernier cycle de vérification complet.
+	MonitorCycleDuration = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "monitor",
+		Name:      "last_cycle_duration_seconds",
+		Help:      "Durée du dernier cycle de vérification complet.",
+	})
+
+	// MonitorCheckDuration mesure la durée d'une vérification d'URL.
+	MonitorCheckDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "monitor",
+		Name:      "check_duration_seconds",
+		Help:      "Durée d'une vérification d'URL.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+	})
+)
+
+// Valeurs des labels 'result' et 'state' des métriques du moniteur.
+const (
+	MonitorUp   = "up"
+	MonitorDown = "down"
+)
+
+// Valeurs du label 'result' de la métrique des notifications.
+const (
+	NotificationSent   = "sent"
+	NotificationFailed = "failed"
+)
+
+// RegisterClickQueue expose la profondeur et la capacité du channel des événements de clic.
+// Elle doit être appelée une seule fois, après la création du channel.
+func RegisterClickQueue(clickEvents chan models.ClickEvent) {
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "click_events_queue_depth",
+		Help:      "Nombre d'événements de clic en attente dans le channel des workers.",
+	}, func() float64 { return float64(len(clickEvents)) })
+
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "click_events_queue_capacity",
+		Help:      "Capacité du channel des événements de clic.",
+	}, func() float64 { return float64(cap(clickEvents)) })
+}
+
+// RegisterLinkCache expose les compteurs du cache des liens. Elle doit être appelée une seule fois.
+func RegisterLinkCache(stats func() cache.Stats) {
+	opts := func(name, help string) prometheus.CounterOpts {
+		return prometheus.CounterOpts{Namespace: namespace, Subsystem: "link_cache", Name: name, Help: help}
+	}
+	promauto.NewCounterFunc(opts("hits_total", "Recherches de codes courts servies par le cache."),
+		func() float64 { return float64(stats().Hits) })
+	promauto.NewCounterFunc(opts("misses_total", "Recherches de codes courts absentes du cache."),
+		func() float64 { return float64(stats().Misses) })
+	promauto.NewCounterFunc(opts("evictions_total", "Entrées évincées du cache faute de place."),
+		func() float64 { return float64(stats().Evictions) })
+
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "link_cache",
+		Name:      "entries",
+		Help:      "Nombre de codes courts en cache.",
+	}, func() float64 { return float64(stats().Size) })
+}
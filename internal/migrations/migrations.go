@@ -0,0 +1,324 @@
+package migrations
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// embedded contient les migrations : des fichiers SQL embarqués dans l'exécutable, rangés par moteur de base de données :
+//
+//	sql/<moteur>/<version>_<nom>.up.sql    appliqué par 'migrate up'
+//	sql/<moteur>/<version>_<nom>.down.sql  appliqué par 'migrate down'
+//
+// La version est un entier croissant (0001, 0002, ...). Les instructions d'un fichier sont séparées
+// par un point-virgule en fin de ligne. Chaque migration est exécutée dans une transaction
+// (sous MySQL, les instructions DDL valident toutefois implicitement la transaction).
+//
+//go:embed sql
+var embedded embed.FS
+
+// Drivers pour lesquels des migrations sont fournies (noms des dialectes GORM).
+var Drivers = []string{"sqlite", "postgres", "mysql"}
+
+// ErrUnknownDriver indique qu'aucune migration n'existe pour le moteur de la base.
+var ErrUnknownDriver = errors.New("aucune migration pour ce moteur de base de données")
+
+// fileNamePattern reconnaît les noms de fichiers de migration.
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration est une évolution versionnée du schéma.
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status associe une migration à sa date d'application (nil si elle n'a pas été appliquée).
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// schemaMigration est une ligne de la table schema_migrations (migrations appliquées).
+type schemaMigration struct {
+	Version   uint64    `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"size:255;not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+// TableName fixe le nom de la table de suivi des migrations.
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migrator applique et annule les migrations embarquées sur une base de données.
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration // Triées par version croissante
+}
+
+// NewMigrator charge les migrations correspondant au moteur de 'db' et crée si besoin
+// la table schema_migrations.
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+	migrations, err := load(embedded, path.Join("sql", db.Dialector.Name()))
+	if err != nil {
+		return nil, err
+	}
+	if err := db.AutoMigrate(&schemaMigration{}); err != nil {
+		return nil, fmt.Errorf("impossible de créer la table schema_migrations: %w", err)
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Status renvoie toutes les migrations connues avec leur état d'application.
+func (m *Migrator) Status() ([]Status, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Migration: migration}
+		if row, ok := applied[migration.Version]; ok {
+			appliedAt := row.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Pending renvoie les migrations qui n'ont pas encore été appliquées.
+func (m *Migrator) Pending() ([]Migration, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// Up applique toutes les migrations en attente, par version croissante.
+// Elle s'arrête à la première erreur et renvoie les migrations appliquées jusque-là.
+func (m *Migrator) Up() ([]Migration, error) {
+	pending, err := m.Pending()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, migration := range pending {
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := execScript(tx, migration.Up); err != nil {
+				return err
+			}
+			return tx.Create(&schemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now().UTC()}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("échec de la migration %04d_%s: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Down annule les n dernières migrations appliquées, de la plus récente à la plus ancienne.
+func (m *Migrator) Down(n int) ([]Migration, error) {
+	var rows []schemaMigration
+	if err := m.db.Order("version DESC").Limit(n).Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("impossible de lire la table schema_migrations: %w", err)
+	}
+
+	byVersion := make(map[uint64]Migration, len(m.migrations))
+	for _, migration := range m.migrations {
+		byVersion[migration.Version] = migration
+	}
+
+	var done []Migration
+	for _, row := range rows {
+		migration, ok := byVersion[row.Version]
+		if !ok {
+			return done, fmt.Errorf("la migration appliquée %04d_%s est inconnue de cette version du programme", row.Version, row.Name)
+		}
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := execScript(tx, migration.Down); err != nil {
+				return err
+			}
+			return tx.Delete(&schemaMigration{}, row.Version).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("échec de l'annulation de la migration %04d_%s: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// applied renvoie les migrations enregistrées dans schema_migrations, indexées par version.
+func (m *Migrator) applied() (map[uint64]schemaMigration, error) {
+	var rows []schemaMigration
+	if err := m.db.Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("impossible de lire la table schema_migrations: %w", err)
+	}
+	applied := make(map[uint64]schemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+// Create ajoute une nouvelle migration vide nommée 'name' dans le répertoire source 'dir',
+// pour chaque moteur pris en charge, avec la version suivant la plus récente existante.
+// Les fichiers étant embarqués, le programme doit être recompilé pour les prendre en compte.
+func Create(dir, name string) ([]string, error) {
+	name = normalizeName(name)
+	if name == "" {
+		return nil, fmt.Errorf("nom de migration invalide")
+	}
+
+	var next uint64 = 1
+	for _, driver := range Drivers {
+		migrations, err := load(os.DirFS(dir), driver)
+		if err != nil && !errors.Is(err, ErrUnknownDriver) {
+			return nil, err
+		}
+		if len(migrations) > 0 && migrations[len(migrations)-1].Version >= next {
+			next = migrations[len(migrations)-1].Version + 1
+		}
+	}
+
+	var created []string
+	for _, driver := range Drivers {
+		driverDir := filepath.Join(dir, driver)
+		if err := os.MkdirAll(driverDir, 0o755); err != nil {
+			return created, fmt.Errorf("impossible de créer le répertoire '%s': %w", driverDir, err)
+		}
+		for _, direction := range []string{"up", "down"} {
+			file := filepath.Join(driverDir, fmt.Sprintf("%04d_%s.%s.sql", next, name, direction))
+			header := fmt.Sprintf("-- Migration %04d_%s (%s, %s)\n", next, name, driver, direction)
+			if err := os.WriteFile(file, []byte(header), 0o644); err != nil {
+				return created, fmt.Errorf("impossible de créer le fichier '%s': %w", file, err)
+			}
+			created = append(created, file)
+		}
+	}
+	return created, nil
+}
+
+// load lit les migrations du répertoire 'dir' d'un système de fichiers, triées par version.
+// Chaque migration doit avoir un fichier up et un fichier down.
+func load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownDriver, path.Base(dir))
+		}
+		return nil, fmt.Errorf("impossible de lire les migrations: %w", err)
+	}
+
+	byVersion := make(map[uint64]*Migration)
+	for _, entry := range entries {
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("version de migration invalide: %s", entry.Name())
+		}
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("impossible de lire la migration %s: %w", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("deux migrations portent la version %04d (%s et %s)", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("la migration %04d_%s doit avoir un fichier up et un fichier down", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// execScript exécute une à une les instructions d'un fichier de migration.
+func execScript(tx *gorm.DB, script string) error {
+	for _, statement := range splitStatements(script) {
+		if err := tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitStatements découpe un script SQL en instructions terminées par un point-virgule en fin de ligne.
+// Les lignes de commentaire (--) sont ignorées.
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
+
+// normalizeName convertit un nom libre en nom de fichier de migration (minuscules, chiffres et _).
+func normalizeName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == ' ', r == '-', r == '_':
+			b.WriteRune('_')
+		}
+	}
+	return strings.Trim(b.String(), "_")
+}
@@ -0,0 +1,179 @@
+package migrations
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/config"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"gorm.io/gorm"
+)
+
+// baselineSQLiteSchema est le schéma créé par GORM AutoMigrate dans les versions antérieures
+// aux migrations (modèles Link et Click d'origine), tel que relevé dans sqlite_master.
+var baselineSQLiteSchema = []string{
+	"CREATE TABLE `links` (`id` integer PRIMARY KEY AUTOINCREMENT,`short_code` text NOT NULL,`long_url` text NOT NULL,`created_at` datetime NOT NULL)",
+	"CREATE UNIQUE INDEX `idx_links_short_code` ON `links`(`short_code`)",
+	"CREATE TABLE `clicks` (`id` integer PRIMARY KEY AUTOINCREMENT,`link_id` integer,`timestamp` datetime,`user_agent` text,`ip_address` text,CONSTRAINT `fk_clicks_link` FOREIGN KEY (`link_id`) REFERENCES `links`(`id`))",
+	"CREATE INDEX `idx_clicks_link_id` ON `clicks`(`link_id`)",
+}
+
+// openSQLite ouvre via database.Open une base SQLite dans un fichier temporaire.
+func openSQLite(t *testing.T) *gorm.DB {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Database.Driver = database.DriverSQLite
+	cfg.Database.Name = filepath.Join(t.TempDir(), "url_shortener.db")
+
+	db, err := database.Open(cfg)
+	if err != nil {
+		t.Fatalf("ouverture de la base: %v", err)
+	}
+	t.Cleanup(func() { database.Close(db) })
+	return db
+}
+
+// assertSchemaMatchesModels vérifie que chaque colonne des modèles existe dans la base.
+func assertSchemaMatchesModels(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	for _, model := range []interface{}{&models.Link{}, &models.Click{}, &models.APIKey{}, &models.LinkCheck{}} {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			t.Fatal(err)
+		}
+		for _, column := range stmt.Schema.DBNames {
+			if !db.Migrator().HasColumn(model, column) {
+				t.Errorf("colonne %s.%s absente après les migrations", stmt.Schema.Table, column)
+			}
+		}
+	}
+}
+
+func TestMigrateBaselineDatabase(t *testing.T) {
+	db := openSQLite(t)
+	for _, statement := range baselineSQLiteSchema {
+		if err := db.Exec(statement).Error; err != nil {
+			t.Fatalf("création du schéma d'origine: %v", err)
+		}
+	}
+	createdAt := time.Date(2025, time.June, 1, 12, 0, 0, 0, time.UTC)
+	if err := db.Exec("INSERT INTO links (short_code, long_url, created_at) VALUES (?, ?, ?)", "abc123", "https://example.com", createdAt).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Exec("INSERT INTO clicks (link_id, timestamp, user_agent, ip_address) VALUES (1, ?, 'Mozilla/5.0', '203.0.113.1')", createdAt).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	applied, err := migrator.Up()
+	if err != nil {
+		t.Fatalf("mise à niveau de la base d'origine: %v", err)
+	}
+	if len(applied) != len(migrator.migrations) {
+		t.Errorf("%d migration(s) appliquée(s) sur %d", len(applied), len(migrator.migrations))
+	}
+	assertSchemaMatchesModels(t, db)
+
+	// Les données existantes sont conservées et lisibles avec les modèles actuels
+	var link models.Link
+	if err := db.Where("short_code = ?", "abc123").First(&link).Error; err != nil {
+		t.Fatalf("lien d'origine introuvable: %v", err)
+	}
+	if link.ExpiresAt != nil || link.OwnerKeyID != nil || link.Notify {
+		t.Errorf("valeurs par défaut inattendues pour le lien d'origine: %+v", link)
+	}
+	var click models.Click
+	if err := db.First(&click).Error; err != nil {
+		t.Fatalf("clic d'origine introuvable: %v", err)
+	}
+	if click.LinkID != link.ID || click.IsBot || click.IPAddress != "203.0.113.1" {
+		t.Errorf("clic d'origine altéré: %+v", click)
+	}
+	// Les dimensions ajoutées valent une chaîne vide, comme pour les clics enregistrés ensuite
+	var nullDimensions int64
+	if err := db.Model(&models.Click{}).
+		Where("referrer IS NULL OR browser IS NULL OR os IS NULL OR device_type IS NULL OR visitor_hash IS NULL").
+		Count(&nullDimensions).Error; err != nil {
+		t.Fatal(err)
+	}
+	if nullDimensions != 0 {
+		t.Errorf("%d clic(s) d'origine avec des dimensions NULL", nullDimensions)
+	}
+
+	// Retour complet en arrière puis nouvelle application sur la base vidée
+	reverted, err := migrator.Down(len(applied))
+	if err != nil {
+		t.Fatalf("annulation des migrations: %v", err)
+	}
+	if len(reverted) != len(applied) {
+		t.Errorf("%d migration(s) annulée(s) sur %d", len(reverted), len(applied))
+	}
+	for _, table := range []string{"links", "clicks", "api_keys", "link_checks"} {
+		if db.Migrator().HasTable(table) {
+			t.Errorf("table %s toujours présente après l'annulation de toutes les migrations", table)
+		}
+	}
+	if pending, err := migrator.Pending(); err != nil || len(pending) != len(applied) {
+		t.Errorf("%d migration(s) en attente après l'annulation, %d attendues (%v)", len(pending), len(applied), err)
+	}
+
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("nouvelle application des migrations: %v", err)
+	}
+	assertSchemaMatchesModels(t, db)
+}
+
+func TestMigrateDownOneByOne(t *testing.T) {
+	db := openSQLite(t)
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Chaque migration doit pouvoir être annulée seule, de la plus récente à la plus ancienne
+	for i := len(migrator.migrations) - 1; i >= 0; i-- {
+		migration := migrator.migrations[i]
+		reverted, err := migrator.Down(1)
+		if err != nil {
+			t.Fatalf("annulation de %04d_%s: %v", migration.Version, migration.Name, err)
+		}
+		if len(reverted) != 1 || reverted[0].Version != migration.Version {
+			t.Fatalf("annulation de %04d_%s: %v annulée(s)", migration.Version, migration.Name, reverted)
+		}
+	}
+	if pending, err := migrator.Pending(); err != nil || len(pending) != len(migrator.migrations) {
+		t.Errorf("%d migration(s) en attente, %d attendues (%v)", len(pending), len(migrator.migrations), err)
+	}
+}
+
+func TestMigrationsExistForEveryDriver(t *testing.T) {
+	var versions []uint64
+	for _, driver := range Drivers {
+		migrations, err := load(embedded, "sql/"+driver)
+		if err != nil {
+			t.Fatalf("%s: %v", driver, err)
+		}
+		if versions == nil {
+			for _, migration := range migrations {
+				versions = append(versions, migration.Version)
+			}
+			continue
+		}
+		if len(migrations) != len(versions) {
+			t.Fatalf("%s: %d migration(s), %d attendues", driver, len(migrations), len(versions))
+		}
+		for i, migration := range migrations {
+			if migration.Version != versions[i] {
+				t.Errorf("%s: migration %04d au lieu de %04d", driver, migration.Version, versions[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,19 @@
+-- Schéma initial : liens et clics, tels que créés par GORM AutoMigrate avant l'introduction des migrations.
+-- IF NOT EXISTS permet d'adopter une base créée par ces versions : les migrations suivantes la mettent à niveau.
+CREATE TABLE IF NOT EXISTS `links` (
+    `id` bigint unsigned AUTO_INCREMENT PRIMARY KEY,
+    `short_code` varchar(10) NOT NULL,
+    `long_url` longtext NOT NULL,
+    `created_at` datetime(3) NOT NULL,
+    UNIQUE INDEX `idx_links_short_code` (`short_code`)
+);
+
+CREATE TABLE IF NOT EXISTS `clicks` (
+    `id` bigint unsigned AUTO_INCREMENT PRIMARY KEY,
+    `link_id` bigint unsigned NULL,
+    `timestamp` datetime(3) NULL,
+    `user_agent` varchar(255),
+    `ip_address` varchar(50),
+    INDEX `idx_clicks_link_id` (`link_id`),
+    CONSTRAINT `fk_clicks_link` FOREIGN KEY (`link_id`) REFERENCES `links` (`id`)
+);
@@ -0,0 +1 @@
+ALTER TABLE `links` MODIFY `short_code` varchar(10) NOT NULL;
@@ -0,0 +1,4 @@
+-- Alias personnalisés : les codes courts passent de 10 à 32 caractères.
+-- Les codes courts sont sensibles à la casse ("abc" et "ABC" sont deux liens) : la collation
+-- par défaut de MySQL ne l'étant pas, short_code utilise utf8mb4_bin.
+ALTER TABLE `links` MODIFY `short_code` varchar(32) COLLATE utf8mb4_bin NOT NULL;
@@ -0,0 +1 @@
+ALTER TABLE `links` DROP COLUMN `deleted_at`;
@@ -0,0 +1,3 @@
+ALTER TABLE `links` DROP COLUMN `owner_key_id`;
+
+DROP TABLE IF EXISTS `api_keys`;
@@ -0,0 +1,15 @@
+-- Clés d'API et rattachement des liens à la clé qui les a créés.
+CREATE TABLE `api_keys` (
+    `id` bigint unsigned AUTO_INCREMENT PRIMARY KEY,
+    `name` varchar(100) NOT NULL,
+    `prefix` varchar(16) NOT NULL,
+    `key_hash` varchar(64) NOT NULL,
+    `created_at` datetime(3) NOT NULL,
+    `last_used_at` datetime(3) NULL,
+    `revoked_at` datetime(3) NULL,
+    UNIQUE INDEX `idx_api_keys_key_hash` (`key_hash`),
+    INDEX `idx_api_keys_revoked_at` (`revoked_at`)
+);
+
+ALTER TABLE `links` ADD COLUMN `owner_key_id` bigint unsigned NULL;
+CREATE INDEX `idx_links_owner_key_id` ON `links` (`owner_key_id`);
@@ -0,0 +1,4 @@
+ALTER TABLE `clicks` DROP COLUMN `device_type`;
+ALTER TABLE `clicks` DROP COLUMN `os`;
+ALTER TABLE `clicks` DROP COLUMN `browser_version`;
+ALTER TABLE `clicks` DROP COLUMN `browser`;
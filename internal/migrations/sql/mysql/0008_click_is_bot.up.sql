@@ -0,0 +1,3 @@
+-- Clics attribués à des robots, exclus des statistiques par défaut.
+ALTER TABLE `clicks` ADD COLUMN `is_bot` boolean NOT NULL DEFAULT false;
+CREATE INDEX `idx_clicks_is_bot` ON `clicks` (`is_bot`);
@@ -0,0 +1,16 @@
+-- Historique des vérifications du moniteur et dernier état connu de chaque lien.
+CREATE TABLE `link_checks` (
+    `id` bigint unsigned AUTO_INCREMENT PRIMARY KEY,
+    `link_id` bigint unsigned NOT NULL,
+    `checked_at` datetime(3) NOT NULL,
+    `up` boolean NOT NULL,
+    `status_code` int NOT NULL DEFAULT 0,
+    `latency_ms` bigint NOT NULL DEFAULT 0,
+    `error` varchar(255),
+    INDEX `idx_link_checks_link_checked` (`link_id`, `checked_at`),
+    INDEX `idx_link_checks_checked_at` (`checked_at`),
+    CONSTRAINT `fk_link_checks_link` FOREIGN KEY (`link_id`) REFERENCES `links` (`id`)
+);
+
+ALTER TABLE `links` ADD COLUMN `last_check_up` boolean NULL;
+ALTER TABLE `links` ADD COLUMN `last_checked_at` datetime(3) NULL;
@@ -0,0 +1,2 @@
+DROP INDEX `idx_clicks_spool_id` ON `clicks`;
+ALTER TABLE `clicks` DROP COLUMN `spool_id`;
@@ -0,0 +1,4 @@
+-- Identifiant unique des clics rejoués depuis le spool : un segment rejoué deux fois
+-- (arrêt brutal entre l'enregistrement et la suppression du segment) n'insère pas de doublons.
+ALTER TABLE `clicks` ADD COLUMN `spool_id` varchar(32) NULL;
+CREATE UNIQUE INDEX `idx_clicks_spool_id` ON `clicks` (`spool_id`);
@@ -0,0 +1,19 @@
+-- Schéma initial : liens et clics, tels que créés par GORM AutoMigrate avant l'introduction des migrations.
+-- IF NOT EXISTS permet d'adopter une base créée par ces versions : les migrations suivantes la mettent à niveau.
+CREATE TABLE IF NOT EXISTS links (
+    id bigserial PRIMARY KEY,
+    short_code varchar(10) NOT NULL,
+    long_url text NOT NULL,
+    created_at timestamptz NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_links_short_code ON links (short_code);
+
+CREATE TABLE IF NOT EXISTS clicks (
+    id bigserial PRIMARY KEY,
+    link_id bigint,
+    "timestamp" timestamptz,
+    user_agent varchar(255),
+    ip_address varchar(50),
+    CONSTRAINT fk_clicks_link FOREIGN KEY (link_id) REFERENCES links (id)
+);
+CREATE INDEX IF NOT EXISTS idx_clicks_link_id ON clicks (link_id);
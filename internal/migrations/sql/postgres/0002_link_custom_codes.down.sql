@@ -0,0 +1 @@
+ALTER TABLE links ALTER COLUMN short_code TYPE varchar(10);
@@ -0,0 +1,2 @@
+-- Alias personnalisés : les codes courts passent de 10 à 32 caractères.
+ALTER TABLE links ALTER COLUMN short_code TYPE varchar(32);
@@ -0,0 +1,3 @@
+-- Date d'expiration optionnelle des liens.
+ALTER TABLE links ADD COLUMN expires_at timestamptz;
+CREATE INDEX idx_links_expires_at ON links (expires_at);
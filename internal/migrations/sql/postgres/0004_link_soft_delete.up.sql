@@ -0,0 +1,3 @@
+-- Suppression logique des liens (corbeille).
+ALTER TABLE links ADD COLUMN deleted_at timestamptz;
+CREATE INDEX idx_links_deleted_at ON links (deleted_at);
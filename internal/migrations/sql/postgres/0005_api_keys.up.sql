@@ -0,0 +1,15 @@
+-- Clés d'API et rattachement des liens à la clé qui les a créés.
+CREATE TABLE api_keys (
+    id bigserial PRIMARY KEY,
+    name varchar(100) NOT NULL,
+    prefix varchar(16) NOT NULL,
+    key_hash varchar(64) NOT NULL,
+    created_at timestamptz NOT NULL,
+    last_used_at timestamptz,
+    revoked_at timestamptz
+);
+CREATE UNIQUE INDEX idx_api_keys_key_hash ON api_keys (key_hash);
+CREATE INDEX idx_api_keys_revoked_at ON api_keys (revoked_at);
+
+ALTER TABLE links ADD COLUMN owner_key_id bigint;
+CREATE INDEX idx_links_owner_key_id ON links (owner_key_id);
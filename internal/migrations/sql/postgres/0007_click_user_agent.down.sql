@@ -0,0 +1,4 @@
+ALTER TABLE clicks DROP COLUMN IF EXISTS device_type;
+ALTER TABLE clicks DROP COLUMN IF EXISTS os;
+ALTER TABLE clicks DROP COLUMN IF EXISTS browser_version;
+ALTER TABLE clicks DROP COLUMN IF EXISTS browser;
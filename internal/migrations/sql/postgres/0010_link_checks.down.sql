@@ -0,0 +1,3 @@
+ALTER TABLE links DROP COLUMN IF EXISTS last_checked_at;
+ALTER TABLE links DROP COLUMN IF EXISTS last_check_up;
+DROP TABLE IF EXISTS link_checks;
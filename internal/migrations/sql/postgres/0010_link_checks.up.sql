@@ -0,0 +1,16 @@
+-- Historique des vérifications du moniteur et dernier état connu de chaque lien.
+CREATE TABLE link_checks (
+    id bigserial PRIMARY KEY,
+    link_id bigint NOT NULL,
+    checked_at timestamptz NOT NULL,
+    up boolean NOT NULL,
+    status_code integer NOT NULL DEFAULT 0,
+    latency_ms bigint NOT NULL DEFAULT 0,
+    error varchar(255),
+    CONSTRAINT fk_link_checks_link FOREIGN KEY (link_id) REFERENCES links (id)
+);
+CREATE INDEX idx_link_checks_link_checked ON link_checks (link_id, checked_at);
+CREATE INDEX idx_link_checks_checked_at ON link_checks (checked_at);
+
+ALTER TABLE links ADD COLUMN last_check_up boolean;
+ALTER TABLE links ADD COLUMN last_checked_at timestamptz;
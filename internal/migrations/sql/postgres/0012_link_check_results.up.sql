@@ -0,0 +1,14 @@
+-- Classification des vérifications du moniteur, méthode utilisée et redirections suivies.
+ALTER TABLE link_checks ADD COLUMN result varchar(20) NOT NULL DEFAULT '';
+ALTER TABLE link_checks ADD COLUMN method varchar(4) NOT NULL DEFAULT '';
+ALTER TABLE link_checks ADD COLUMN final_url text;
+ALTER TABLE link_checks ADD COLUMN redirect_chain text;
+
+-- Les vérifications existantes ne sont classées que lorsque leur code de statut le permet.
+UPDATE link_checks SET result = CASE
+    WHEN status_code BETWEEN 200 AND 299 THEN 'ok'
+    WHEN status_code BETWEEN 300 AND 399 THEN 'redirect'
+    WHEN status_code BETWEEN 400 AND 499 THEN 'client_error'
+    WHEN status_code >= 500 THEN 'server_error'
+    ELSE ''
+END, method = 'HEAD';
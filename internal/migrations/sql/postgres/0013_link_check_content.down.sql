@@ -0,0 +1,5 @@
+ALTER TABLE links DROP COLUMN content_fingerprint;
+
+ALTER TABLE link_checks DROP COLUMN content_fingerprint;
+ALTER TABLE link_checks DROP COLUMN content_hash;
+ALTER TABLE link_checks DROP COLUMN cert_expires_at;
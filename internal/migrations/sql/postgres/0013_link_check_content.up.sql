@@ -0,0 +1,6 @@
+-- Expiration des certificats TLS et empreintes du contenu des destinations surveillées.
+ALTER TABLE link_checks ADD COLUMN cert_expires_at timestamptz;
+ALTER TABLE link_checks ADD COLUMN content_hash varchar(64) NOT NULL DEFAULT '';
+ALTER TABLE link_checks ADD COLUMN content_fingerprint varchar(16) NOT NULL DEFAULT '';
+
+ALTER TABLE links ADD COLUMN content_fingerprint varchar(16) NOT NULL DEFAULT '';
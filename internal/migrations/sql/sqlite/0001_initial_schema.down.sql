@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `clicks`;
+DROP TABLE IF EXISTS `links`;
@@ -0,0 +1 @@
+-- Rien à faire : voir la migration up.
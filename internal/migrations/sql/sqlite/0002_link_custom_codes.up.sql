@@ -0,0 +1,2 @@
+-- Alias personnalisés : les codes courts passent de 10 à 32 caractères.
+-- Rien à faire : sous SQLite, le type text n'a pas de longueur maximale.
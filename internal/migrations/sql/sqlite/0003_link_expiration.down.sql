@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS `idx_links_expires_at`;
+ALTER TABLE `links` DROP COLUMN `expires_at`;
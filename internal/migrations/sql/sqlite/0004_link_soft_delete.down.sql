@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS `idx_links_deleted_at`;
+ALTER TABLE `links` DROP COLUMN `deleted_at`;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS `idx_links_owner_key_id`;
+ALTER TABLE `links` DROP COLUMN `owner_key_id`;
+
+DROP TABLE IF EXISTS `api_keys`;
@@ -0,0 +1,15 @@
+-- Clés d'API et rattachement des liens à la clé qui les a créés.
+CREATE TABLE `api_keys` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `name` text NOT NULL,
+    `prefix` text NOT NULL,
+    `key_hash` text NOT NULL,
+    `created_at` datetime NOT NULL,
+    `last_used_at` datetime,
+    `revoked_at` datetime
+);
+CREATE UNIQUE INDEX `idx_api_keys_key_hash` ON `api_keys`(`key_hash`);
+CREATE INDEX `idx_api_keys_revoked_at` ON `api_keys`(`revoked_at`);
+
+ALTER TABLE `links` ADD COLUMN `owner_key_id` integer;
+CREATE INDEX `idx_links_owner_key_id` ON `links`(`owner_key_id`);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS `idx_clicks_referrer`;
+ALTER TABLE `clicks` DROP COLUMN `referrer`;
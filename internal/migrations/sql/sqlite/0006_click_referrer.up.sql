@@ -0,0 +1,4 @@
+-- Hôte de la page d'origine des clics.
+-- Les clics existants reçoivent une chaîne vide (trafic direct), comme ceux enregistrés ensuite.
+ALTER TABLE `clicks` ADD COLUMN `referrer` text DEFAULT '';
+CREATE INDEX `idx_clicks_referrer` ON `clicks`(`referrer`);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS `idx_clicks_device_type`;
+DROP INDEX IF EXISTS `idx_clicks_os`;
+DROP INDEX IF EXISTS `idx_clicks_browser`;
+ALTER TABLE `clicks` DROP COLUMN `device_type`;
+ALTER TABLE `clicks` DROP COLUMN `os`;
+ALTER TABLE `clicks` DROP COLUMN `browser_version`;
+ALTER TABLE `clicks` DROP COLUMN `browser`;
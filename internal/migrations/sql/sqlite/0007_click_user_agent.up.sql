@@ -0,0 +1,9 @@
+-- Dimensions des clics extraites du User-Agent.
+-- Les clics existants reçoivent une chaîne vide (valeur inconnue), comme ceux enregistrés ensuite.
+ALTER TABLE `clicks` ADD COLUMN `browser` text DEFAULT '';
+ALTER TABLE `clicks` ADD COLUMN `browser_version` text DEFAULT '';
+ALTER TABLE `clicks` ADD COLUMN `os` text DEFAULT '';
+ALTER TABLE `clicks` ADD COLUMN `device_type` text DEFAULT '';
+CREATE INDEX `idx_clicks_browser` ON `clicks`(`browser`);
+CREATE INDEX `idx_clicks_os` ON `clicks`(`os`);
+CREATE INDEX `idx_clicks_device_type` ON `clicks`(`device_type`);
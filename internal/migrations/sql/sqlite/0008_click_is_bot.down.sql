@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS `idx_clicks_is_bot`;
+ALTER TABLE `clicks` DROP COLUMN `is_bot`;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS `idx_clicks_visitor_hash`;
+ALTER TABLE `clicks` DROP COLUMN `visitor_hash`;
@@ -0,0 +1,4 @@
+-- Empreinte quotidienne des visiteurs (visiteurs uniques).
+-- Les clics existants, enregistrés sans empreinte, reçoivent une chaîne vide.
+ALTER TABLE `clicks` ADD COLUMN `visitor_hash` text DEFAULT '';
+CREATE INDEX `idx_clicks_visitor_hash` ON `clicks`(`visitor_hash`);
@@ -0,0 +1,16 @@
+-- Historique des vérifications du moniteur et dernier état connu de chaque lien.
+CREATE TABLE `link_checks` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `link_id` integer NOT NULL,
+    `checked_at` datetime NOT NULL,
+    `up` numeric NOT NULL,
+    `status_code` integer NOT NULL DEFAULT 0,
+    `latency_ms` integer NOT NULL DEFAULT 0,
+    `error` text,
+    CONSTRAINT `fk_link_checks_link` FOREIGN KEY (`link_id`) REFERENCES `links`(`id`)
+);
+CREATE INDEX `idx_link_checks_link_checked` ON `link_checks`(`link_id`, `checked_at`);
+CREATE INDEX `idx_link_checks_checked_at` ON `link_checks`(`checked_at`);
+
+ALTER TABLE `links` ADD COLUMN `last_check_up` numeric;
+ALTER TABLE `links` ADD COLUMN `last_checked_at` datetime;
@@ -0,0 +1,2 @@
+-- Activation, lien par lien, des notifications de changement d'état du moniteur.
+ALTER TABLE `links` ADD COLUMN `notify` numeric NOT NULL DEFAULT false;
@@ -0,0 +1,4 @@
+ALTER TABLE `link_checks` DROP COLUMN `redirect_chain`;
+ALTER TABLE `link_checks` DROP COLUMN `final_url`;
+ALTER TABLE `link_checks` DROP COLUMN `method`;
+ALTER TABLE `link_checks` DROP COLUMN `result`;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS `idx_clicks_spool_id`;
+ALTER TABLE `clicks` DROP COLUMN `spool_id`;
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// APIKey représente une clé d'API autorisée à appeler les routes /api/v1.
+// Seule l'empreinte SHA-256 de la clé est stockée : le secret n'est affiché qu'une fois, à sa création.
+type APIKey struct {
+	ID         uint       `gorm:"primarykey"`
+	Name       string     `gorm:"size:100;not null"`
+	Prefix     string     `gorm:"size:16;not null"`             // Début de la clé, pour l'identifier sans révéler le secret
+	KeyHash    string     `gorm:"uniqueIndex;size:64;not null"` // Empreinte SHA-256 (hexadécimale) du secret
+	CreatedAt  time.Time  `gorm:"not null"`
+	LastUsedAt *time.Time // Date de dernière utilisation
+	RevokedAt  *time.Time `gorm:"index"` // Date de révocation (nil = clé active)
+}
+
+// IsRevoked indique si la clé a été révoquée.
+func (k *APIKey) IsRevoked() bool {
+	return k.RevokedAt != nil
+}
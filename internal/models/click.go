@@ -11,6 +11,19 @@ type Click struct {
 	Timestamp time.Time
 	UserAgent string `gorm:"size:255"`
 	IPAddress string `gorm:"size:50"`
+	Referrer  string `gorm:"size:255;index"` // Hôte de la page d'origine (vide = trafic direct)
+
+	// Dimensions extraites du User-Agent par les workers
+	Browser        string `gorm:"size:50;index"`
+	BrowserVersion string `gorm:"size:20"`
+	OS             string `gorm:"size:50;index"`
+	DeviceType     string `gorm:"size:20;index"` // desktop, mobile, tablet ou bot
+
+	IsBot bool `gorm:"index;not null;default:false"` // Clic attribué à un robot (exclu des statistiques par défaut)
+
+	VisitorHash string `gorm:"size:32;index"` // Empreinte du visiteur (IP + User-Agent, sel quotidien)
+
+	SpoolID *string `gorm:"size:32;uniqueIndex"` // Identifiant de l'événement rejoué depuis le spool (nil sinon)
 }
 
 // ClickEvent représente un événement de clic brut, destiné à être passé via un channel
@@ -20,4 +33,7 @@ type ClickEvent struct {
 	Timestamp time.Time
 	UserAgent string
 	IPAddress string
+	Referrer  string // Hôte extrait de l'en-tête Referer
+	IsBot     bool   // Requête identifiée comme non humaine (HEAD, préchargement...)
+	SpoolID   string // Identifiant unique attribué lors de l'écriture dans le spool (vide sinon)
 }
@@ -1,12 +1,40 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // Link représente un lien raccourci dans la base de données.
 // Les tags `gorm:"..."` définissent comment GORM doit mapper cette structure à une table SQL.
 type Link struct {
-	ID        uint      `gorm:"primarykey"`
-	ShortCode string    `gorm:"uniqueIndex;size:10;not null"`
-	LongURL   string    `gorm:"not null"`
-	CreatedAt time.Time `gorm:"not null"`
+	ID         uint           `gorm:"primarykey"`
+	ShortCode  string         `gorm:"uniqueIndex;size:32;not null"` // Sensible à la casse (COLLATE utf8mb4_bin sous MySQL, voir les migrations)
+	LongURL    string         `gorm:"not null"`
+	CreatedAt  time.Time      `gorm:"not null"`
+	ExpiresAt  *time.Time     `gorm:"index"`                  // Date d'expiration optionnelle (nil = le lien n'expire jamais)
+	DeletedAt  gorm.DeletedAt `gorm:"index"`                  // Suppression logique : le lien est dans la corbeille s'il est renseigné
+	OwnerKeyID *uint          `gorm:"index"`                  // Clé d'API propriétaire (nil = lien créé via la CLI)
+	Notify     bool           `gorm:"not null;default:false"` // Notifier les changements d'état de la destination
+
+	// Dernier état connu de la destination, tenu à jour par le moniteur (nil = jamais vérifiée),
+	// et dernière empreinte de son contenu, référence de la détection des changements
+	LastCheckUp        *bool
+	LastCheckedAt      *time.Time
+	ContentFingerprint string `gorm:"size:16;not null;default:''"`
+}
+
+// IsOwnedBy indique si le lien est visible pour la clé d'API donnée.
+// Une clé nil correspond à un accès administrateur (CLI ou authentification désactivée).
+func (l *Link) IsOwnedBy(ownerKeyID *uint) bool {
+	if ownerKeyID == nil {
+		return true
+	}
+	return l.OwnerKeyID != nil && *l.OwnerKeyID == *ownerKeyID
+}
+
+// IsExpired indique si le lien a expiré à l'instant donné.
+func (l *Link) IsExpired(now time.Time) bool {
+	return l.ExpiresAt != nil && !now.Before(*l.ExpiresAt)
 }
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Classification du résultat d'une vérification (LinkCheck.Result).
+const (
+	CheckResultOK              = "ok"               // Réponse 2xx, sans redirection vers un autre site
+	CheckResultRedirect        = "redirect"         // Redirection vers un autre site, 3xx final ou trop de redirections
+	CheckResultClientError     = "client_error"     // Réponse 4xx
+	CheckResultServerError     = "server_error"     // Réponse 5xx
+	CheckResultTimeout         = "timeout"          // Délai dépassé
+	CheckResultDNS             = "dns"              // Nom de domaine introuvable
+	CheckResultTLS             = "tls"              // Échec de la négociation TLS (certificat invalide, expiré...)
+	CheckResultConnectionError = "connection_error" // Autre erreur réseau (connexion refusée, adresse interdite...)
+)
+
+// LinkCheck représente le résultat d'une vérification de l'URL de destination d'un lien par le moniteur.
+type LinkCheck struct {
+	ID                 uint          `gorm:"primaryKey"`
+	LinkID             uint          `gorm:"not null;index:idx_link_checks_link_checked,priority:1"`
+	CheckedAt          time.Time     `gorm:"not null;index:idx_link_checks_link_checked,priority:2;index"`
+	Up                 bool          `gorm:"not null"`                    // La destination était accessible
+	Result             string        `gorm:"size:20;not null;default:''"` // Classification du résultat (CheckResult*)
+	Method             string        `gorm:"size:4;not null;default:''"`  // HEAD, ou GET partiel si le serveur refuse HEAD
+	StatusCode         int           `gorm:"not null;default:0"`          // Code de statut HTTP final (0 si aucune réponse)
+	FinalURL           string        `gorm:"type:text"`                   // URL ayant donné la réponse finale, après redirections
+	RedirectChain      []RedirectHop `gorm:"type:text;serializer:json"`   // Redirections suivies, dans l'ordre
+	CertExpiresAt      *time.Time    // Expiration du certificat TLS expirant le plus tôt le long des redirections (nil sans HTTPS)
+	ContentHash        string        `gorm:"size:64;not null;default:''"` // SHA-256 du corps de la page (vide si non analysé)
+	ContentFingerprint string        `gorm:"size:16;not null;default:''"` // Empreinte SimHash du texte : des pages proches ont des empreintes proches
+	LatencyMs          int64         `gorm:"not null;default:0"`          // Durée de la vérification en millisecondes
+	Error              string        `gorm:"size:255"`                    // Erreur réseau éventuelle
+}
+
+// RedirectHop est une redirection suivie lors d'une vérification.
+type RedirectHop struct {
+	StatusCode int    `json:"status_code"` // Code de la réponse de redirection (301, 302...)
+	Location   string `json:"location"`    // URL vers laquelle la réponse redirige
+}
@@ -0,0 +1,80 @@
+package monitor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"html"
+	"math/bits"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// shingleSize est le nombre de mots consécutifs hachés ensemble pour calculer l'empreinte :
+// un mot modifié ne change que quelques fragments, alors qu'une page remplacée les change tous.
+const shingleSize = 3
+
+var (
+	// invisibleBlocks retire les scripts, styles et commentaires, dont le contenu n'est pas affiché.
+	invisibleBlocks = regexp.MustCompile(`(?is)<script\b.*?</script>|<style\b.*?</style>|<!--.*?-->`)
+	// htmlTags retire les balises restantes pour ne garder que le texte.
+	htmlTags = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+// contentHash renvoie le SHA-256 (hexadécimal) du corps d'une page : il change à la moindre modification.
+func contentHash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// contentFingerprint calcule l'empreinte SimHash (64 bits, hexadécimal) du texte visible d'une page.
+// Deux versions proches d'une page (date, compteur...) ont des empreintes qui diffèrent de peu de bits.
+// Elle renvoie une chaîne vide si la page ne contient aucun texte.
+func contentFingerprint(body []byte) string {
+	text := htmlTags.ReplaceAllString(invisibleBlocks.ReplaceAllString(string(body), " "), " ")
+	words := strings.FieldsFunc(strings.ToLower(html.UnescapeString(text)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return ""
+	}
+
+	var weights [64]int
+	size := min(shingleSize, len(words))
+	for i := 0; i+size <= len(words); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(words[i:i+size], " ")))
+		sum := h.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	var fingerprint uint64
+	for bit, weight := range weights {
+		if weight > 0 {
+			fingerprint |= 1 << bit
+		}
+	}
+	return fmt.Sprintf("%016x", fingerprint)
+}
+
+// fingerprintDistance renvoie le nombre de bits différents (0 à 64) entre deux empreintes.
+func fingerprintDistance(a, b string) (int, error) {
+	x, err := strconv.ParseUint(a, 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("empreinte invalide '%s': %w", a, err)
+	}
+	y, err := strconv.ParseUint(b, 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("empreinte invalide '%s': %w", b, err)
+	}
+	return bits.OnesCount64(x ^ y), nil
+}
@@ -0,0 +1,79 @@
+package monitor
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// hostLimiter limite le nombre de vérifications simultanées vers un même hôte,
+// pour ne pas saturer un site qui héberge beaucoup de destinations.
+type hostLimiter struct {
+	limit int
+	mu    sync.Mutex
+	hosts map[string]*hostSlots // Sémaphores des hôtes en cours de vérification
+}
+
+// hostSlots est le sémaphore d'un hôte et le nombre de vérifications qui l'utilisent ou l'attendent.
+type hostSlots struct {
+	sem   chan struct{}
+	users int
+}
+
+// newHostLimiter crée un limiteur autorisant limit vérifications simultanées par hôte (au moins 1).
+func newHostLimiter(limit int) *hostLimiter {
+	if limit < 1 {
+		limit = 1
+	}
+	return &hostLimiter{limit: limit, hosts: make(map[string]*hostSlots)}
+}
+
+// acquire réserve une place pour l'hôte, en attendant si nécessaire.
+// Elle renvoie false, sans rien réserver, si stop est fermé pendant l'attente.
+func (l *hostLimiter) acquire(host string, stop <-chan struct{}) bool {
+	l.mu.Lock()
+	slots, ok := l.hosts[host]
+	if !ok {
+		slots = &hostSlots{sem: make(chan struct{}, l.limit)}
+		l.hosts[host] = slots
+	}
+	slots.users++
+	l.mu.Unlock()
+
+	select {
+	case slots.sem <- struct{}{}:
+		return true
+	case <-stop:
+		l.forget(host, slots)
+		return false
+	}
+}
+
+// release libère la place réservée par acquire.
+func (l *hostLimiter) release(host string) {
+	l.mu.Lock()
+	slots := l.hosts[host]
+	l.mu.Unlock()
+	<-slots.sem
+	l.forget(host, slots)
+}
+
+// forget retire l'hôte de la map quand plus aucune vérification ne l'utilise.
+func (l *hostLimiter) forget(host string, slots *hostSlots) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	slots.users--
+	if slots.users == 0 {
+		delete(l.hosts, host)
+	}
+}
+
+// hostKey renvoie l'hôte d'une URL, en minuscules, pour regrouper ses vérifications.
+// Une URL illisible est sa propre clé : elle ne sera de toute façon pas contactée.
+func hostKey(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return rawURL
+	}
+	return strings.ToLower(u.Hostname())
+}
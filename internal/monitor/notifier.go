@@ -0,0 +1,78 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/config"
+)
+
+// Types d'événements émis par le moniteur.
+const (
+	EventStateChanged        = "link.state_changed"        // La destination est devenue accessible ou inaccessible
+	EventCertificateExpiring = "link.certificate_expiring" // Le certificat TLS de la destination expire bientôt
+	EventContentChanged      = "link.content_changed"      // Le contenu de la destination a changé de manière importante
+)
+
+// États d'une destination dans les événements.
+const (
+	StateUp   = "up"
+	StateDown = "down"
+)
+
+// Event décrit un événement du moniteur transmis aux notificateurs.
+type Event struct {
+	Type            string     `json:"event"`
+	LinkID          uint       `json:"link_id"`
+	ShortCode       string     `json:"short_code"`
+	LongURL         string     `json:"long_url"`
+	PreviousState   string     `json:"previous_state,omitempty"`
+	State           string     `json:"state,omitempty"`
+	StatusCode      int        `json:"status_code,omitempty"`            // Code HTTP de la dernière vérification (0 sans réponse)
+	Result          string     `json:"result,omitempty"`                 // Classification de la dernière vérification (ok, dns, timeout...)
+	Error           string     `json:"error,omitempty"`                  // Erreur réseau de la dernière vérification
+	CertExpiresAt   *time.Time `json:"certificate_expires_at,omitempty"` // Expiration du certificat (link.certificate_expiring)
+	ContentDistance int        `json:"content_distance,omitempty"`       // Bits d'empreinte différents sur 64 (link.content_changed)
+	Message         string     `json:"message"`                          // Résumé lisible de l'événement
+	OccurredAt      time.Time  `json:"occurred_at"`
+}
+
+// Notifier transmet les événements du moniteur vers un canal externe.
+// Notify doit respecter l'annulation du contexte.
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, event Event) error
+}
+
+// LoadNotifiers construit les notificateurs configurés dans la section notifications.
+// Un notificateur n'est actif que si sa destination (URL, serveur ou commande) est renseignée.
+func LoadNotifiers(cfg *config.Config) ([]Notifier, error) {
+	var notifiers []Notifier
+
+	webhook := cfg.Notifications.Webhook
+	if webhook.URL != "" {
+		if webhook.Secret == "" {
+			return nil, fmt.Errorf("notifications.webhook.secret est requis pour signer les requêtes")
+		}
+		notifiers = append(notifiers, NewWebhookNotifier(webhook.URL, webhook.Secret, webhook.MaxRetries,
+			time.Duration(webhook.TimeoutSeconds)*time.Second))
+	}
+
+	smtpCfg := cfg.Notifications.SMTP
+	if smtpCfg.Host != "" {
+		if smtpCfg.From == "" || len(smtpCfg.To) == 0 {
+			return nil, fmt.Errorf("notifications.smtp.from et notifications.smtp.to sont requis")
+		}
+		notifiers = append(notifiers, NewSMTPNotifier(smtpCfg.Host, smtpCfg.Port, smtpCfg.Username, smtpCfg.Password,
+			smtpCfg.From, smtpCfg.To))
+	}
+
+	command := cfg.Notifications.Command
+	if command.Path != "" {
+		notifiers = append(notifiers, NewCommandNotifier(command.Path, command.Args,
+			time.Duration(command.TimeoutSeconds)*time.Second))
+	}
+
+	return notifiers, nil
+}
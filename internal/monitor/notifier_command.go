@@ -0,0 +1,76 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxCommandOutput borne la sortie de la commande reprise dans les messages d'erreur.
+const maxCommandOutput = 512
+
+// CommandNotifier exécute une commande locale pour chaque événement. L'événement est transmis
+// en JSON sur l'entrée standard et résumé dans les variables d'environnement URLSHORTENER_EVENT,
+// URLSHORTENER_SHORT_CODE, URLSHORTENER_LONG_URL, URLSHORTENER_STATE, URLSHORTENER_PREVIOUS_STATE,
+// URLSHORTENER_STATUS_CODE et URLSHORTENER_MESSAGE. Un code de sortie non nul est une erreur.
+type CommandNotifier struct {
+	path    string
+	args    []string
+	timeout time.Duration
+}
+
+// NewCommandNotifier crée un notificateur qui exécute 'path' avec les arguments 'args'.
+// timeout borne la durée d'exécution (0 = limitée par le contexte seulement).
+func NewCommandNotifier(path string, args []string, timeout time.Duration) *CommandNotifier {
+	return &CommandNotifier{path: path, args: args, timeout: timeout}
+}
+
+// Name renvoie le nom du notificateur.
+func (n *CommandNotifier) Name() string {
+	return "command"
+}
+
+// Notify exécute la commande et attend sa fin.
+func (n *CommandNotifier) Notify(ctx context.Context, event Event) error {
+	if n.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, n.timeout)
+		defer cancel()
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("impossible d'encoder l'événement: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, n.path, n.args...)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Env = append(os.Environ(),
+		"URLSHORTENER_EVENT="+event.Type,
+		"URLSHORTENER_SHORT_CODE="+event.ShortCode,
+		"URLSHORTENER_LONG_URL="+event.LongURL,
+		"URLSHORTENER_STATE="+event.State,
+		"URLSHORTENER_PREVIOUS_STATE="+event.PreviousState,
+		"URLSHORTENER_STATUS_CODE="+strconv.Itoa(event.StatusCode),
+		"URLSHORTENER_MESSAGE="+event.Message,
+	)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		out := strings.TrimSpace(string(output))
+		if out == "" {
+			return fmt.Errorf("la commande '%s' a échoué: %w", n.path, err)
+		}
+		if len(out) > maxCommandOutput {
+			out = out[:maxCommandOutput] + "..."
+		}
+		return fmt.Errorf("la commande '%s' a échoué: %w: %s", n.path, err, out)
+	}
+	return nil
+}
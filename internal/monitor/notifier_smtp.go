@@ -0,0 +1,118 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPNotifier envoie les événements par e-mail. La connexion passe en TLS (STARTTLS)
+// dès que le serveur le propose ; l'authentification n'est utilisée que si un utilisateur est configuré.
+type SMTPNotifier struct {
+	host     string
+	addr     string
+	username string
+	password string
+	from     string
+	to       []string
+}
+
+// NewSMTPNotifier crée un notificateur e-mail.
+func NewSMTPNotifier(host string, port int, username, password, from string, to []string) *SMTPNotifier {
+	return &SMTPNotifier{
+		host:     host,
+		addr:     net.JoinHostPort(host, strconv.Itoa(port)),
+		username: username,
+		password: password,
+		from:     from,
+		to:       to,
+	}
+}
+
+// Name renvoie le nom du notificateur.
+func (n *SMTPNotifier) Name() string {
+	return "smtp"
+}
+
+// Notify envoie un e-mail décrivant l'événement à tous les destinataires.
+func (n *SMTPNotifier) Notify(ctx context.Context, event Event) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", n.addr)
+	if err != nil {
+		return fmt.Errorf("connexion au serveur SMTP impossible: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, n.host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("dialogue SMTP impossible: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: n.host}); err != nil {
+			return fmt.Errorf("STARTTLS a échoué: %w", err)
+		}
+	}
+	if n.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", n.username, n.password, n.host)); err != nil {
+			return fmt.Errorf("authentification SMTP refusée: %w", err)
+		}
+	}
+
+	if err := client.Mail(n.from); err != nil {
+		return fmt.Errorf("expéditeur refusé: %w", err)
+	}
+	for _, to := range n.to {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("destinataire '%s' refusé: %w", to, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("envoi du message impossible: %w", err)
+	}
+	if _, err := w.Write(n.message(event)); err != nil {
+		w.Close()
+		return fmt.Errorf("envoi du message impossible: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("message refusé par le serveur SMTP: %w", err)
+	}
+	return client.Quit()
+}
+
+// message construit l'e-mail (en-têtes et corps en texte brut UTF-8).
+func (n *SMTPNotifier) message(event Event) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", n.from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(n.to, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", "[url-shortener] "+event.Message))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+
+	fmt.Fprintf(&buf, "%s\r\n\r\n", event.Message)
+	fmt.Fprintf(&buf, "Lien : %s\r\n", event.ShortCode)
+	fmt.Fprintf(&buf, "Destination : %s\r\n", event.LongURL)
+	if event.StatusCode != 0 {
+		fmt.Fprintf(&buf, "Statut HTTP : %d\r\n", event.StatusCode)
+	}
+	if event.Error != "" {
+		fmt.Fprintf(&buf, "Erreur : %s\r\n", event.Error)
+	}
+	fmt.Fprintf(&buf, "Date : %s\r\n", event.OccurredAt.UTC().Format(time.RFC3339))
+	return buf.Bytes()
+}
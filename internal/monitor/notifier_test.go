@@ -0,0 +1,349 @@
+package monitor
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testEvent renvoie un événement de changement d'état avec un message accentué.
+func testEvent() Event {
+	return Event{
+		Type:          EventStateChanged,
+		LinkID:        1,
+		ShortCode:     "abc123",
+		LongURL:       "https://example.com/page",
+		PreviousState: StateUp,
+		State:         StateDown,
+		StatusCode:    503,
+		Message:       "Le lien abc123 est devenu inaccessible (réponse 503)",
+		OccurredAt:    time.Date(2026, time.October, 14, 9, 0, 0, 0, time.UTC),
+	}
+}
+
+// fastWebhookBackoff réduit le délai entre deux tentatives le temps du test.
+func fastWebhookBackoff(t *testing.T) {
+	t.Helper()
+	previous := webhookBaseBackoff
+	webhookBaseBackoff = time.Millisecond
+	t.Cleanup(func() { webhookBaseBackoff = previous })
+}
+
+func TestWebhookNotifierSignsTimestampAndBody(t *testing.T) {
+	const secret = "s3cr3t"
+	var (
+		timestamp, signature, contentType string
+		body                              []byte
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timestamp = r.Header.Get(WebhookTimestampHeader)
+		signature = r.Header.Get(WebhookSignatureHeader)
+		contentType = r.Header.Get("Content-Type")
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	event := testEvent()
+	if err := NewWebhookNotifier(server.URL, secret, 0, time.Second).Notify(context.Background(), event); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	// Signature recalculée comme le ferait le destinataire
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + string(body)))
+	if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); signature != want {
+		t.Errorf("signature '%s', '%s' attendue", signature, want)
+	}
+
+	sentAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		t.Fatalf("horodatage invalide '%s': %v", timestamp, err)
+	}
+	if drift := time.Since(time.Unix(sentAt, 0)); drift < -time.Second || drift > time.Minute {
+		t.Errorf("horodatage %s éloigné de l'heure courante (%v)", timestamp, drift)
+	}
+	if contentType != "application/json" {
+		t.Errorf("Content-Type '%s', application/json attendu", contentType)
+	}
+
+	var received Event
+	if err := json.Unmarshal(body, &received); err != nil {
+		t.Fatalf("corps illisible: %v", err)
+	}
+	if received.Type != event.Type || received.ShortCode != event.ShortCode || received.State != event.State {
+		t.Errorf("événement reçu %+v, attendu %+v", received, event)
+	}
+}
+
+func TestWebhookNotifierRetries(t *testing.T) {
+	fastWebhookBackoff(t)
+
+	tests := []struct {
+		name     string
+		statuses []int // Réponses successives ; la dernière est répétée
+		wantErr  bool
+		attempts int
+	}{
+		{"5xx puis succès", []int{503, 200}, false, 2},
+		{"429 retentés", []int{429, 429, 200}, false, 3},
+		{"5xx persistant", []int{500}, true, 3},
+		{"400 non retenté", []int{400, 200}, true, 1},
+		{"404 non retenté", []int{404, 200}, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mu       sync.Mutex
+				attempts int
+			)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				status := tt.statuses[min(attempts, len(tt.statuses)-1)]
+				attempts++
+				mu.Unlock()
+				w.WriteHeader(status)
+			}))
+			defer server.Close()
+
+			err := NewWebhookNotifier(server.URL, "secret", 2, time.Second).Notify(context.Background(), testEvent())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Notify = %v, erreur attendue: %v", err, tt.wantErr)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if attempts != tt.attempts {
+				t.Errorf("%d tentative(s), %d attendue(s)", attempts, tt.attempts)
+			}
+		})
+	}
+}
+
+// smtpSession enregistre le dialogue reçu par le faux serveur SMTP.
+type smtpSession struct {
+	commands []string
+	data     string
+}
+
+// startFakeSMTP démarre un serveur SMTP minimal acceptant une seule connexion.
+// Il n'annonce pas STARTTLS : le notificateur reste donc en clair. La session est
+// transmise sur le canal à la fin du dialogue.
+func startFakeSMTP(t *testing.T) (int, <-chan smtpSession) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("écoute impossible: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	sessions := make(chan smtpSession, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+		var session smtpSession
+		defer func() { sessions <- session }()
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+		reply("220 fake.test ESMTP")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			session.commands = append(session.commands, line)
+
+			switch verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0]); verb {
+			case "EHLO", "HELO", "MAIL", "RCPT":
+				reply("250 OK")
+			case "DATA":
+				reply("354 fin par <CRLF>.<CRLF>")
+				var data strings.Builder
+				for {
+					dataLine, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if dataLine == ".\r\n" {
+						break
+					}
+					data.WriteString(dataLine)
+				}
+				session.data = data.String()
+				reply("250 message accepté")
+			case "QUIT":
+				reply("221 au revoir")
+				return
+			default:
+				reply("502 commande non gérée")
+			}
+		}
+	}()
+	return listener.Addr().(*net.TCPAddr).Port, sessions
+}
+
+func TestSMTPNotifierSendsMessage(t *testing.T) {
+	port, sessions := startFakeSMTP(t)
+	to := []string{"ops@example.com", "astreinte@example.com"}
+	notifier := NewSMTPNotifier("127.0.0.1", port, "", "", "monitor@example.com", to)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	event := testEvent()
+	if err := notifier.Notify(ctx, event); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	session := <-sessions
+
+	var mail string
+	var rcpts []string
+	for _, command := range session.commands {
+		switch {
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			mail = command
+		case strings.HasPrefix(command, "RCPT TO:"):
+			rcpts = append(rcpts, command)
+		}
+	}
+	if !strings.HasPrefix(mail, "MAIL FROM:<monitor@example.com>") {
+		t.Errorf("commande MAIL '%s'", mail)
+	}
+	if len(rcpts) != len(to) || rcpts[0] != "RCPT TO:<ops@example.com>" || rcpts[1] != "RCPT TO:<astreinte@example.com>" {
+		t.Errorf("commandes RCPT %v", rcpts)
+	}
+	if last := session.commands[len(session.commands)-1]; last != "QUIT" {
+		t.Errorf("dernière commande '%s', QUIT attendu", last)
+	}
+
+	headers, body, _ := strings.Cut(session.data, "\r\n\r\n")
+	var subject string
+	for _, header := range strings.Split(headers, "\r\n") {
+		if value, ok := strings.CutPrefix(header, "Subject: "); ok {
+			subject = value
+		}
+	}
+	if !strings.HasPrefix(subject, "=?utf-8?q?") {
+		t.Errorf("sujet non encodé: '%s'", subject)
+	}
+	decoded, err := new(mime.WordDecoder).DecodeHeader(subject)
+	if err != nil {
+		t.Fatalf("sujet illisible '%s': %v", subject, err)
+	}
+	if want := "[url-shortener] " + event.Message; decoded != want {
+		t.Errorf("sujet '%s', '%s' attendu", decoded, want)
+	}
+	if !strings.Contains(headers, "To: ops@example.com, astreinte@example.com") {
+		t.Errorf("en-tête To absent:\n%s", headers)
+	}
+	if !strings.Contains(body, event.Message) || !strings.Contains(body, "Statut HTTP : 503") {
+		t.Errorf("corps incomplet:\n%s", body)
+	}
+}
+
+// writeScript crée un script shell exécutable dans un répertoire temporaire.
+func writeScript(t *testing.T, script string) string {
+	t.Helper()
+	if _, err := os.Stat("/bin/sh"); err != nil {
+		t.Skip("/bin/sh indisponible")
+	}
+	path := filepath.Join(t.TempDir(), "notify.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCommandNotifierPassesEnvAndStdin(t *testing.T) {
+	dir := t.TempDir()
+	envFile, stdinFile := filepath.Join(dir, "env"), filepath.Join(dir, "stdin")
+	script := writeScript(t, `env | grep '^URLSHORTENER_' > "$1"
+cat > "$2"
+`)
+
+	event := testEvent()
+	if err := NewCommandNotifier(script, []string{envFile, stdinFile}, 5*time.Second).Notify(context.Background(), event); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	env, err := os.ReadFile(envFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"URLSHORTENER_EVENT=link.state_changed",
+		"URLSHORTENER_SHORT_CODE=abc123",
+		"URLSHORTENER_LONG_URL=https://example.com/page",
+		"URLSHORTENER_STATE=down",
+		"URLSHORTENER_PREVIOUS_STATE=up",
+		"URLSHORTENER_STATUS_CODE=503",
+		"URLSHORTENER_MESSAGE=" + event.Message,
+	} {
+		if !strings.Contains(string(env), want+"\n") {
+			t.Errorf("variable %s absente de l'environnement:\n%s", want, env)
+		}
+	}
+
+	stdin, err := os.ReadFile(stdinFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var received Event
+	if err := json.Unmarshal(stdin, &received); err != nil {
+		t.Fatalf("entrée standard illisible '%s': %v", stdin, err)
+	}
+	if received.Type != event.Type || received.ShortCode != event.ShortCode || received.Message != event.Message ||
+		!received.OccurredAt.Equal(event.OccurredAt) {
+		t.Errorf("événement reçu %+v, attendu %+v", received, event)
+	}
+}
+
+func TestCommandNotifierNonZeroExit(t *testing.T) {
+	script := writeScript(t, `echo "destination injoignable" >&2
+exit 3
+`)
+
+	err := NewCommandNotifier(script, nil, 5*time.Second).Notify(context.Background(), testEvent())
+	if err == nil {
+		t.Fatal("code de sortie non nul accepté")
+	}
+	if !strings.Contains(err.Error(), "exit status 3") || !strings.Contains(err.Error(), "destination injoignable") {
+		t.Errorf("erreur '%v' sans le code de sortie ni la sortie de la commande", err)
+	}
+}
+
+func TestCommandNotifierTruncatesOutput(t *testing.T) {
+	script := writeScript(t, `i=0
+while [ $i -lt 100 ]; do printf 'xxxxxxxxxx'; i=$((i+1)); done
+exit 1
+`)
+
+	err := NewCommandNotifier(script, nil, 5*time.Second).Notify(context.Background(), testEvent())
+	if err == nil {
+		t.Fatal("code de sortie non nul accepté")
+	}
+	if !strings.HasSuffix(err.Error(), strings.Repeat("x", maxCommandOutput)+"...") ||
+		strings.Contains(err.Error(), strings.Repeat("x", maxCommandOutput+1)) {
+		t.Errorf("sortie non tronquée à %d octets: %v", maxCommandOutput, err)
+	}
+}
@@ -0,0 +1,134 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// webhookBaseBackoff est le délai avant la première nouvelle tentative ; il double à chaque essai.
+// Variable pour que les tests puissent le réduire.
+var webhookBaseBackoff = time.Second
+
+// En-têtes des requêtes du webhook.
+const (
+	WebhookTimestampHeader = "X-Urlshortener-Timestamp"
+	WebhookSignatureHeader = "X-Urlshortener-Signature"
+)
+
+// WebhookNotifier envoie les événements en JSON (POST) vers une URL.
+// Chaque requête est signée : l'en-tête X-Urlshortener-Signature vaut "sha256=" suivi du HMAC-SHA256
+// en hexadécimal, calculé avec le secret partagé sur "<timestamp>.<corps>", où timestamp est la valeur
+// (secondes Unix) de l'en-tête X-Urlshortener-Timestamp. Le destinataire peut ainsi vérifier
+// l'origine du message et refuser les requêtes rejouées.
+// Les erreurs réseau, les réponses 5xx et 429 sont retentées avec un délai exponentiel.
+type WebhookNotifier struct {
+	url        string
+	secret     []byte
+	maxRetries int
+	client     *http.Client
+}
+
+// webhookStatusError signale une réponse HTTP en échec du destinataire.
+type webhookStatusError struct {
+	statusCode int
+}
+
+func (e *webhookStatusError) Error() string {
+	return fmt.Sprintf("le webhook a répondu %d", e.statusCode)
+}
+
+// NewWebhookNotifier crée un notificateur webhook. timeout borne chaque tentative.
+func NewWebhookNotifier(url, secret string, maxRetries int, timeout time.Duration) *WebhookNotifier {
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+	return &WebhookNotifier{
+		url:        url,
+		secret:     []byte(secret),
+		maxRetries: maxRetries,
+		client:     &http.Client{Timeout: timeout},
+	}
+}
+
+// Name renvoie le nom du notificateur.
+func (n *WebhookNotifier) Name() string {
+	return "webhook"
+}
+
+// Notify envoie l'événement, en le retentant au plus maxRetries fois.
+func (n *WebhookNotifier) Notify(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("impossible d'encoder l'événement: %w", err)
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := n.send(ctx, body)
+		if err == nil {
+			return nil
+		}
+		if attempt >= n.maxRetries || !isRetryable(err) {
+			return err
+		}
+
+		select {
+		case <-time.After(webhookBaseBackoff << attempt):
+		case <-ctx.Done():
+			return fmt.Errorf("%w (dernière erreur: %v)", ctx.Err(), err)
+		}
+	}
+}
+
+// send effectue une tentative d'envoi signée.
+func (n *WebhookNotifier) send(ctx context.Context, body []byte) error {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("requête webhook invalide: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, "sha256="+n.sign(timestamp, body))
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024)) // Permet la réutilisation de la connexion
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &webhookStatusError{statusCode: resp.StatusCode}
+	}
+	return nil
+}
+
+// sign calcule la signature HMAC-SHA256 de "<timestamp>.<corps>".
+func (n *WebhookNotifier) sign(timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, n.secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// isRetryable indique si un échec d'envoi est temporaire : erreur réseau, 429 ou 5xx.
+// Les autres réponses 4xx signalent une erreur de configuration et ne sont pas retentées.
+func isRetryable(err error) bool {
+	var statusErr *webhookStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.statusCode == http.StatusTooManyRequests || statusErr.statusCode >= 500
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,221 @@
+package monitor
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// maxDrainedBody borne la lecture du corps d'une réponse avant sa fermeture,
+// ce qui permet de réutiliser la connexion sans télécharger une page entière.
+const maxDrainedBody = 4 << 10
+
+// errTooManyRedirects est renvoyée quand la chaîne de redirections dépasse la limite configurée.
+var errTooManyRedirects = errors.New("trop de redirections")
+
+// checkUrl vérifie l'accessibilité d'une URL et renvoie le résultat de la vérification (sans LinkID).
+// Une requête HEAD est d'abord envoyée ; si le serveur la refuse (403, 405 ou 501), la vérification
+// est refaite avec un GET limité au premier octet. Les redirections sont suivies et enregistrées,
+// dans la limite de m.maxRedirects. Si l'analyse du contenu est activée, la page finale d'une destination
+// accessible est ensuite téléchargée pour calculer ses empreintes.
+func (m *UrlMonitor) checkUrl(rawURL string) models.LinkCheck {
+	start := time.Now()
+	check := models.LinkCheck{CheckedAt: start.UTC(), Method: http.MethodHead}
+
+	resp, err := m.follow(http.MethodHead, rawURL, &check)
+	if err == nil && headRejected(resp.StatusCode) {
+		check.Method = http.MethodGet
+		resp, err = m.follow(http.MethodGet, rawURL, &check)
+	}
+	check.LatencyMs = time.Since(start).Milliseconds()
+
+	if err != nil {
+		log.Printf("[MONITOR] Erreur d'accès à l'URL '%s': %v", rawURL, err)
+		check.Result = classifyError(err)
+		check.Error = truncate(err.Error(), maxCheckErrorLength)
+		return check
+	}
+
+	check.StatusCode = resp.StatusCode
+	check.FinalURL = resp.Request.URL.String()
+	check.Result, check.Up = classifyResponse(resp, rawURL, len(check.RedirectChain) > 0)
+	if m.contentMaxBytes > 0 && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		m.fetchContent(check.FinalURL, &check)
+	}
+	return check
+}
+
+// follow envoie une requête et suit ses redirections une à une pour les enregistrer dans check,
+// ainsi que l'expiration des certificats TLS rencontrés.
+// La réponse renvoyée est la première qui n'est pas une redirection ; son corps est déjà fermé.
+func (m *UrlMonitor) follow(method, rawURL string, check *models.LinkCheck) (*http.Response, error) {
+	check.RedirectChain = nil
+	target := rawURL
+	for {
+		req, err := http.NewRequest(method, target, nil)
+		if err != nil {
+			return nil, err
+		}
+		if method == http.MethodGet {
+			req.Header.Set("Range", "bytes=0-0") // Seule l'accessibilité compte : inutile de télécharger la page
+		}
+
+		resp, err := m.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainedBody))
+		resp.Body.Close()
+		recordCertificate(resp, check)
+
+		location, err := resp.Location()
+		if !isRedirect(resp.StatusCode) || err != nil {
+			// Une redirection sans en-tête Location valide est une réponse finale
+			return resp, nil
+		}
+		check.RedirectChain = append(check.RedirectChain, models.RedirectHop{StatusCode: resp.StatusCode, Location: location.String()})
+		if len(check.RedirectChain) > m.maxRedirects {
+			return nil, fmt.Errorf("%w (plus de %d)", errTooManyRedirects, m.maxRedirects)
+		}
+		target = location.String()
+	}
+}
+
+// recordCertificate retient dans check l'expiration la plus proche des certificats de la chaîne vérifiée.
+// Les autres certificats envoyés par le serveur (anciennes racines, certificats croisés) ne servent pas
+// à la validation : une racine expirée qui n'est plus utilisée ne doit pas déclencher d'alerte.
+// Sans chaîne vérifiée, seul le certificat du serveur est pris en compte.
+func recordCertificate(resp *http.Response, check *models.LinkCheck) {
+	if resp.TLS == nil || len(resp.TLS.PeerCertificates) == 0 {
+		return
+	}
+	chain := resp.TLS.PeerCertificates[:1]
+	if len(resp.TLS.VerifiedChains) > 0 {
+		chain = resp.TLS.VerifiedChains[0]
+	}
+	for _, cert := range chain {
+		if check.CertExpiresAt == nil || cert.NotAfter.Before(*check.CertExpiresAt) {
+			expiresAt := cert.NotAfter.UTC()
+			check.CertExpiresAt = &expiresAt
+		}
+	}
+}
+
+// fetchContent télécharge la page finale (au plus m.contentMaxBytes octets) et enregistre ses empreintes
+// dans check. Seules les pages textuelles (HTML, texte brut...) sont analysées ; un échec n'est que journalisé.
+func (m *UrlMonitor) fetchContent(pageURL string, check *models.LinkCheck) {
+	resp, err := m.client.Get(pageURL)
+	if err != nil {
+		log.Printf("[MONITOR] Impossible de télécharger le contenu de '%s' : %v", pageURL, err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType != "" && !isTextContent(contentType) {
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, m.contentMaxBytes))
+	if err != nil {
+		log.Printf("[MONITOR] Impossible de lire le contenu de '%s' : %v", pageURL, err)
+		return
+	}
+	if contentType == "" && !isTextContent(http.DetectContentType(body)) {
+		return
+	}
+	check.ContentHash = contentHash(body)
+	check.ContentFingerprint = contentFingerprint(body)
+}
+
+// isTextContent indique si un type de contenu est une page textuelle dont l'empreinte a un sens.
+func isTextContent(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(mediaType, "text/") || mediaType == "application/xhtml+xml"
+}
+
+// isRedirect indique si un code de statut est une redirection à suivre.
+func isRedirect(statusCode int) bool {
+	switch statusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// headRejected indique si un code de statut signale un serveur qui refuse les requêtes HEAD.
+func headRejected(statusCode int) bool {
+	return statusCode == http.StatusForbidden || statusCode == http.StatusMethodNotAllowed ||
+		statusCode == http.StatusNotImplemented
+}
+
+// classifyResponse classe la réponse finale d'une vérification et indique si la destination est accessible.
+// Une redirection vers un autre site (ex: domaine parqué) reste accessible mais est classée à part.
+func classifyResponse(resp *http.Response, rawURL string, redirected bool) (result string, up bool) {
+	switch status := resp.StatusCode; {
+	case status >= 200 && status < 300,
+		// Ressource vide demandée par un GET partiel : elle existe bien
+		status == http.StatusRequestedRangeNotSatisfiable && resp.Request.Method == http.MethodGet:
+		if redirected && !sameSite(rawURL, resp.Request.URL) {
+			return models.CheckResultRedirect, true
+		}
+		return models.CheckResultOK, true
+	case status >= 300 && status < 400:
+		return models.CheckResultRedirect, true
+	case status >= 400 && status < 500:
+		return models.CheckResultClientError, false
+	default:
+		return models.CheckResultServerError, false
+	}
+}
+
+// sameSite indique si l'URL finale est sur le même site que l'URL d'origine :
+// même hôte, au préfixe "www." près (ex: redirection de http:// vers https://www.).
+func sameSite(rawURL string, final *url.URL) bool {
+	original, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	site := func(u *url.URL) string {
+		return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	}
+	return site(original) == site(final)
+}
+
+// classifyError classe l'erreur d'une vérification sans réponse HTTP finale.
+func classifyError(err error) string {
+	var dnsErr *net.DNSError
+	var certErr *tls.CertificateVerificationError
+	var recordErr tls.RecordHeaderError
+	var alertErr tls.AlertError
+	var netErr net.Error
+	switch {
+	case errors.Is(err, errTooManyRedirects):
+		return models.CheckResultRedirect
+	case errors.As(err, &dnsErr):
+		return models.CheckResultDNS
+	case errors.As(err, &certErr), errors.As(err, &recordErr), errors.As(err, &alertErr):
+		return models.CheckResultTLS
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return models.CheckResultTimeout
+	default:
+		return models.CheckResultConnectionError
+	}
+}
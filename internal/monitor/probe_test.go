@@ -0,0 +1,52 @@
+package monitor
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+func TestRecordCertificateIgnoresUnusedCertificates(t *testing.T) {
+	expiry := func(year int) *x509.Certificate {
+		return &x509.Certificate{NotAfter: time.Date(year, time.May, 30, 10, 48, 38, 0, time.UTC)}
+	}
+	leaf, intermediate, root := expiry(2027), expiry(2029), expiry(2035)
+	expiredRoot := expiry(2020)          // Ancienne racine croisée encore envoyée par le serveur
+	expiringIntermediate := expiry(2026) // Intermédiaire de la chaîne expirant avant la feuille
+
+	tests := []struct {
+		name  string
+		state *tls.ConnectionState
+		want  *time.Time
+	}{
+		{"sans TLS", nil, nil},
+		{"racine expirée hors de la chaîne vérifiée", &tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{leaf, intermediate, expiredRoot},
+			VerifiedChains:   [][]*x509.Certificate{{leaf, intermediate, root}},
+		}, &leaf.NotAfter},
+		{"intermédiaire expirant en premier", &tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{leaf, expiringIntermediate},
+			VerifiedChains:   [][]*x509.Certificate{{leaf, expiringIntermediate, root}},
+		}, &expiringIntermediate.NotAfter},
+		{"sans chaîne vérifiée", &tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{leaf, expiredRoot},
+		}, &leaf.NotAfter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var check models.LinkCheck
+			recordCertificate(&http.Response{TLS: tt.state}, &check)
+			switch {
+			case tt.want == nil && check.CertExpiresAt != nil:
+				t.Errorf("expiration %v enregistrée sans TLS", check.CertExpiresAt)
+			case tt.want != nil && (check.CertExpiresAt == nil || !check.CertExpiresAt.Equal(*tt.want)):
+				t.Errorf("expiration %v, %v attendue", check.CertExpiresAt, tt.want)
+			}
+		})
+	}
+}
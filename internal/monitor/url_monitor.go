@@ -1,51 +1,177 @@
 package monitor
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"net/http"
 	"sync" // Pour protéger l'accès concurrentiel à knownStates
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
-	_ "github.com/axellelanca/urlshortener/internal/models"   // Importe les modèles de liens
+	"github.com/axellelanca/urlshortener/internal/metrics"
+	"github.com/axellelanca/urlshortener/internal/models"
 	"github.com/axellelanca/urlshortener/internal/repository" // Importe le repository de liens
+	"github.com/axellelanca/urlshortener/internal/urlpolicy"
 )
 
+// checkTimeout borne la durée d'une vérification d'URL.
+const checkTimeout = 5 * time.Second
+
+// maxCheckErrorLength correspond à la taille de la colonne link_checks.error.
+const maxCheckErrorLength = 255
+
+// notifyTimeout borne l'envoi d'un événement par un notificateur, nouvelles tentatives comprises.
+const notifyTimeout = 2 * time.Minute
+
+// Options regroupe les paramètres du moniteur d'URLs.
+type Options struct {
+	Interval               time.Duration     // Intervalle entre chaque vérification (ex: 5 minutes)
+	Workers                int               // Nombre de vérifications simultanées (au moins 1)
+	PerHostLimit           int               // Nombre de vérifications simultanées vers un même hôte (au moins 1)
+	MaxRedirects           int               // Nombre maximum de redirections suivies par vérification
+	CertExpiryWarning      time.Duration     // Alerte quand le certificat TLS expire dans moins de cette durée (0 = désactivée)
+	ContentMaxBytes        int64             // Taille lue des pages pour calculer leur empreinte (0 = analyse du contenu désactivée)
+	ContentChangeThreshold int               // Bits d'empreinte différents (sur 64) à partir desquels le contenu a changé (0 = désactivé)
+	HistoryRetention       time.Duration     // Durée de conservation de l'historique des vérifications (0 = illimitée)
+	URLPolicy              *urlpolicy.Policy // Ses règles d'adresses s'appliquent aux connexions des vérifications
+	Notifiers              []Notifier        // Canaux de notification des événements (liens ayant activé Notify)
+}
+
 // UrlMonitor gère la surveillance périodique des URLs longues.
 type UrlMonitor struct {
-	linkRepo    repository.LinkRepository // Pour récupérer les URLs à surveiller
-	interval    time.Duration             // Intervalle entre chaque vérification (ex: 5 minutes)
-	knownStates map[uint]bool             // État connu de chaque URL: map[LinkID]estAccessible (true/false)
-	mu          sync.Mutex                // Mutex pour protéger l'accès concurrentiel à knownStates
+	linkRepo               repository.LinkRepository      // Pour récupérer les URLs à surveiller
+	checkRepo              repository.LinkCheckRepository // Pour enregistrer l'historique des vérifications
+	interval               time.Duration                  // Intervalle entre chaque vérification (ex: 5 minutes)
+	retention              time.Duration                  // Durée de conservation de l'historique des vérifications
+	workers                int                            // Nombre de vérifications simultanées
+	hosts                  *hostLimiter                   // Limite les vérifications simultanées par hôte
+	maxRedirects           int                            // Nombre maximum de redirections suivies par vérification
+	certWarning            time.Duration                  // Délai avant expiration d'un certificat TLS déclenchant une alerte
+	certWarnings           map[uint]time.Time             // Expiration du certificat déjà signalée, par lien (protégée par mu)
+	contentMaxBytes        int64                          // Taille lue des pages pour calculer leur empreinte
+	contentChangeThreshold int                            // Bits d'empreinte différents signalant un changement de contenu
+	client                 *http.Client                   // Client HTTP partagé par les vérifications, dont le dialer applique la politique des URLs
+	notifiers              []Notifier                     // Canaux de notification des événements
+	notifications          sync.WaitGroup                 // Notifications en cours d'envoi
+	knownStates            map[uint]bool                  // État connu de chaque URL: map[LinkID]estAccessible (true/false)
+	knownURLs              map[uint]string                // URL de destination vérifiée en dernier, par lien (protégée par mu)
+	mu                     sync.Mutex                     // Mutex pour protéger l'accès concurrentiel à knownStates, knownURLs et certWarnings
+	running                atomic.Bool                    // Un cycle de vérification est en cours
+	cycles                 sync.WaitGroup                 // Cycle de vérification en cours
+	stop                   chan struct{}                  // Fermé par Stop pour arrêter la surveillance
+	done                   chan struct{}                  // Fermé quand la boucle de surveillance est terminée
 }
 
 // NewUrlMonitor crée et retourne une nouvelle instance de UrlMonitor.
-func NewUrlMonitor(linkRepo repository.LinkRepository, interval time.Duration) *UrlMonitor {
+// Les connexions des vérifications passent par le dialer de opts.URLPolicy : une destination qui résout
+// (ou redirige) vers une adresse interne est considérée comme inaccessible.
+func NewUrlMonitor(linkRepo repository.LinkRepository, checkRepo repository.LinkCheckRepository, opts Options) *UrlMonitor {
+	if opts.Workers < 1 {
+		opts.Workers = 1
+	}
+	if opts.MaxRedirects < 0 {
+		opts.MaxRedirects = 0
+	}
+	hosts := newHostLimiter(opts.PerHostLimit)
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil // Le contrôle des adresses doit porter sur la destination, pas sur un proxy
+	transport.DialContext = opts.URLPolicy.Dialer(checkTimeout).DialContext
+	transport.MaxIdleConnsPerHost = hosts.limit // Réutilise les connexions des vérifications d'un même hôte
+
 	return &UrlMonitor{
-		linkRepo:    linkRepo,
-		interval:    interval,
+		linkRepo:               linkRepo,
+		checkRepo:              checkRepo,
+		interval:               opts.Interval,
+		retention:              opts.HistoryRetention,
+		workers:                opts.Workers,
+		hosts:                  hosts,
+		maxRedirects:           opts.MaxRedirects,
+		certWarning:            opts.CertExpiryWarning,
+		certWarnings:           make(map[uint]time.Time),
+		contentMaxBytes:        opts.ContentMaxBytes,
+		contentChangeThreshold: opts.ContentChangeThreshold,
+		client: &http.Client{
+			Timeout:   checkTimeout,
+			Transport: transport,
+			// Les redirections sont suivies par checkUrl, qui les enregistre une à une
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		notifiers:   opts.Notifiers,
 		knownStates: make(map[uint]bool),
+		knownURLs:   make(map[uint]string),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
 	}
 }
 
 // Start lance la boucle de surveillance périodique des URLs.
 // Cette fonction est conçue pour être lancée dans une goroutine séparée.
 func (m *UrlMonitor) Start() {
-	log.Printf("[MONITOR] Démarrage du moniteur d'URLs avec un intervalle de %v...", m.interval)
+	log.Printf("[MONITOR] Démarrage du moniteur d'URLs avec un intervalle de %v (%d vérifications simultanées, %d par hôte)...",
+		m.interval, m.workers, m.hosts.limit)
+	defer close(m.done)
 	ticker := time.NewTicker(m.interval)
 	defer ticker.Stop()
 
 	// Exécute une première vérification immédiatement au démarrage
-	m.checkUrls()
+	m.startCycle()
 
 	// Boucle principale du moniteur
-	for range ticker.C {
+	for {
+		select {
+		case <-ticker.C:
+			m.startCycle()
+		case <-m.stop:
+			m.cycles.Wait()
+			log.Println("[MONITOR] Moniteur d'URLs arrêté.")
+			return
+		}
+	}
+}
+
+// startCycle lance un cycle de vérification en arrière-plan, sauf si le précédent n'est pas terminé :
+// le cycle est alors ignoré plutôt que de s'accumuler derrière lui.
+func (m *UrlMonitor) startCycle() {
+	if !m.running.CompareAndSwap(false, true) {
+		metrics.MonitorCyclesSkipped.Inc()
+		log.Printf("[MONITOR] Vérification précédente toujours en cours : cycle ignoré (intervalle de %v trop court ?).", m.interval)
+		return
+	}
+	m.cycles.Add(1)
+	go func() {
+		defer m.cycles.Done()
+		defer m.running.Store(false)
 		m.checkUrls()
+	}()
+}
+
+// Stop arrête la surveillance et attend la fin de la vérification en cours
+// ainsi que l'envoi des notifications en cours.
+// Une vérification en cours s'interrompt sans lancer les liens restants.
+func (m *UrlMonitor) Stop() {
+	close(m.stop)
+	<-m.done
+	m.notifications.Wait()
+}
+
+// stopping indique si l'arrêt du moniteur a été demandé.
+func (m *UrlMonitor) stopping() bool {
+	select {
+	case <-m.stop:
+		return true
+	default:
+		return false
 	}
 }
 
-// checkUrls effectue une vérification de l'état de toutes les URLs longues enregistrées.
+// checkUrls effectue une vérification de l'état de toutes les URLs longues enregistrées,
+// en parallèle sur m.workers goroutines.
 func (m *UrlMonitor) checkUrls() {
 	log.Println("[MONITOR] Lancement de la vérification de l'état des URLs...")
+	started := time.Now()
 
 	links, err := m.linkRepo.GetAllLinks()
 	if err != nil {
@@ -53,46 +179,266 @@ func (m *UrlMonitor) checkUrls() {
 		return
 	}
 
+	jobs := make(chan models.Link)
+	var up, down atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < min(m.workers, len(links)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for link := range jobs {
+				accessible, checked := m.checkLink(link)
+				switch {
+				case !checked:
+				case accessible:
+					up.Add(1)
+				default:
+					down.Add(1)
+				}
+			}
+		}()
+	}
+
+	now := time.Now()
+	interrupted := false
+dispatch:
 	for _, link := range links {
-		currentState := m.isUrlAccessible(link.LongURL)
-
-		// Protéger l'accès à la map 'knownStates'
-		m.mu.Lock()
-		previousState, exists := m.knownStates[link.ID]
-		m.knownStates[link.ID] = currentState
-		m.mu.Unlock()
-
-		// Si c'est la première vérification pour ce lien, on initialise l'état sans notifier
-		if !exists {
-			log.Printf("[MONITOR] État initial pour le lien %s (%s) : %s",
-				link.ShortCode, link.LongURL, formatState(currentState))
+		// Les liens expirés ne redirigent plus : inutile de surveiller leur destination
+		if link.IsExpired(now) {
 			continue
 		}
+		select {
+		case jobs <- link:
+		case <-m.stop:
+			interrupted = true
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if interrupted || m.stopping() {
+		log.Println("[MONITOR] Vérification interrompue : arrêt du moniteur demandé.")
+		return
+	}
+	metrics.MonitorLinks.WithLabelValues(metrics.MonitorUp).Set(float64(up.Load()))
+	metrics.MonitorLinks.WithLabelValues(metrics.MonitorDown).Set(float64(down.Load()))
 
-		// Si l'état a changé, générer une notification
-		if currentState != previousState {
-			log.Printf("[NOTIFICATION] Le lien %s (%s) est passé de %s à %s !",
-				link.ShortCode, link.LongURL,
-				formatState(previousState), formatState(currentState))
+	if m.retention > 0 {
+		if deleted, err := m.checkRepo.DeleteChecksBefore(time.Now().Add(-m.retention)); err != nil {
+			log.Printf("[MONITOR] ERREUR lors de la suppression de l'historique des vérifications : %v", err)
+		} else if deleted > 0 {
+			log.Printf("[MONITOR] %d vérification(s) de plus de %v supprimée(s) de l'historique.", deleted, m.retention)
 		}
 	}
-	log.Println("[MONITOR] Vérification de l'état des URLs terminée.")
+
+	elapsed := time.Since(started)
+	metrics.MonitorCycleDuration.Set(elapsed.Seconds())
+	log.Printf("[MONITOR] Vérification de l'état des URLs terminée en %v (%d accessible(s), %d inaccessible(s)).",
+		elapsed.Round(time.Millisecond), up.Load(), down.Load())
 }
 
-// isUrlAccessible effectue une requête HTTP HEAD pour vérifier l'accessibilité d'une URL.
-func (m *UrlMonitor) isUrlAccessible(url string) bool {
-	client := &http.Client{
-		Timeout: 5 * time.Second,
+// checkLink vérifie la destination d'un lien, enregistre le résultat et signale un éventuel changement d'état,
+// un certificat TLS proche de l'expiration ou un changement important de contenu.
+// checked vaut false si la vérification n'a pas eu lieu (arrêt du moniteur pendant l'attente de l'hôte).
+func (m *UrlMonitor) checkLink(link models.Link) (accessible, checked bool) {
+	host := hostKey(link.LongURL)
+	if !m.hosts.acquire(host, m.stop) {
+		return false, false
 	}
+	check := m.checkUrl(link.LongURL)
+	m.hosts.release(host)
 
-	resp, err := client.Head(url)
-	if err != nil {
-		log.Printf("[MONITOR] Erreur d'accès à l'URL '%s': %v", url, err)
+	// L'état, le certificat et l'empreinte connus d'une destination remplacée ne servent plus de référence
+	if m.forgetPreviousURL(link) {
+		link.LastCheckUp, link.ContentFingerprint = nil, ""
+	}
+
+	check.LinkID = link.ID
+	metrics.MonitorCheckDuration.Observe(float64(check.LatencyMs) / 1000)
+	metrics.MonitorCheckResults.WithLabelValues(check.Result).Inc()
+	if err := m.checkRepo.RecordCheck(&check); err != nil {
+		log.Printf("[MONITOR] ERREUR lors de l'enregistrement de la vérification du lien %s : %v", link.ShortCode, err)
+	}
+
+	currentState := check.Up
+	if currentState {
+		metrics.MonitorChecks.WithLabelValues(metrics.MonitorUp).Inc()
+	} else {
+		metrics.MonitorChecks.WithLabelValues(metrics.MonitorDown).Inc()
+	}
+
+	m.detectStateChange(link, check)
+	m.detectCertificateExpiry(link, check)
+	m.detectContentChange(link, check)
+	return currentState, true
+}
+
+// forgetPreviousURL retient l'URL de destination vérifiée pour le lien et, si elle a changé depuis
+// la vérification précédente, oublie l'état et l'alerte de certificat de l'ancienne destination.
+// L'URL modifiée efface aussi le dernier état enregistré en base (voir UpdateLink), mais une vérification
+// de l'ancienne destination encore en cours à ce moment-là a pu le réécrire : il est alors ignoré.
+func (m *UrlMonitor) forgetPreviousURL(link models.Link) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	previousURL, known := m.knownURLs[link.ID]
+	m.knownURLs[link.ID] = link.LongURL
+	if !known || previousURL == link.LongURL {
 		return false
 	}
-	defer resp.Body.Close()
+	delete(m.knownStates, link.ID)
+	delete(m.certWarnings, link.ID)
+	return true
+}
+
+// detectStateChange compare l'état de la destination à l'état connu et signale un changement.
+func (m *UrlMonitor) detectStateChange(link models.Link, check models.LinkCheck) {
+	currentState := check.Up
+
+	// Protéger l'accès à la map 'knownStates'
+	m.mu.Lock()
+	previousState, exists := m.knownStates[link.ID]
+	m.knownStates[link.ID] = currentState
+	m.mu.Unlock()
+
+	// Après un redémarrage, l'état enregistré lors de la dernière vérification sert de référence
+	if !exists && link.LastCheckUp != nil {
+		previousState, exists = *link.LastCheckUp, true
+	}
 
-	return resp.StatusCode >= 200 && resp.StatusCode < 400
+	// Si c'est la première vérification pour ce lien, on initialise l'état sans notifier
+	if !exists {
+		log.Printf("[MONITOR] État initial pour le lien %s (%s) : %s",
+			link.ShortCode, link.LongURL, formatState(currentState))
+		return
+	}
+
+	// Si l'état a changé, générer une notification
+	if currentState != previousState {
+		log.Printf("[NOTIFICATION] Le lien %s (%s) est passé de %s à %s !",
+			link.ShortCode, link.LongURL,
+			formatState(previousState), formatState(currentState))
+		if link.Notify {
+			event := newEvent(EventStateChanged, link, check, fmt.Sprintf("Le lien %s est maintenant %s (%s)",
+				link.ShortCode, formatState(currentState), link.LongURL))
+			event.PreviousState = eventState(previousState)
+			m.notify(event)
+		}
+	}
+}
+
+// detectCertificateExpiry signale un certificat TLS qui expire dans moins de m.certWarning.
+// L'alerte est émise une fois par certificat (et de nouveau après un redémarrage du serveur).
+func (m *UrlMonitor) detectCertificateExpiry(link models.Link, check models.LinkCheck) {
+	if m.certWarning <= 0 || check.CertExpiresAt == nil {
+		return
+	}
+	expiresAt := *check.CertExpiresAt
+	remaining := expiresAt.Sub(check.CheckedAt)
+	if remaining > m.certWarning {
+		return
+	}
+
+	m.mu.Lock()
+	alreadyWarned := m.certWarnings[link.ID].Equal(expiresAt)
+	m.certWarnings[link.ID] = expiresAt
+	m.mu.Unlock()
+	if alreadyWarned {
+		return
+	}
+
+	days := int(remaining.Hours() / 24)
+	log.Printf("[NOTIFICATION] Le certificat TLS de la destination du lien %s (%s) expire le %s (dans %d jour(s)) !",
+		link.ShortCode, link.LongURL, expiresAt.Format(time.RFC3339), days)
+	if link.Notify {
+		event := newEvent(EventCertificateExpiring, link, check, fmt.Sprintf("Le certificat TLS de la destination du lien %s expire dans %d jour(s) (%s)",
+			link.ShortCode, days, link.LongURL))
+		event.CertExpiresAt = &expiresAt
+		m.notify(event)
+	}
+}
+
+// detectContentChange compare l'empreinte du contenu à la précédente et signale un changement important
+// (au moins m.contentChangeThreshold bits différents sur 64), par exemple une page remplacée par une
+// annonce de vente du domaine.
+func (m *UrlMonitor) detectContentChange(link models.Link, check models.LinkCheck) {
+	if m.contentChangeThreshold <= 0 || check.ContentFingerprint == "" || link.ContentFingerprint == "" {
+		return
+	}
+	distance, err := fingerprintDistance(link.ContentFingerprint, check.ContentFingerprint)
+	if err != nil {
+		log.Printf("[MONITOR] ERREUR lors de la comparaison du contenu du lien %s : %v", link.ShortCode, err)
+		return
+	}
+	if distance < m.contentChangeThreshold {
+		return
+	}
+
+	log.Printf("[NOTIFICATION] Le contenu de la destination du lien %s (%s) a changé (%d bits différents sur 64) !",
+		link.ShortCode, link.LongURL, distance)
+	if link.Notify {
+		event := newEvent(EventContentChanged, link, check, fmt.Sprintf("Le contenu de la destination du lien %s a changé (%s)",
+			link.ShortCode, link.LongURL))
+		event.ContentDistance = distance
+		m.notify(event)
+	}
+}
+
+// truncate coupe une chaîne à maxLen octets pour respecter la taille des colonnes,
+// sans couper un caractère UTF-8 en deux.
+func truncate(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	s = s[:maxLen]
+	for len(s) > 0 && !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+// notify transmet un événement à tous les notificateurs, en arrière-plan pour ne pas retarder
+// les vérifications. Les échecs sont journalisés et comptés dans les métriques.
+func (m *UrlMonitor) notify(event Event) {
+	for _, notifier := range m.notifiers {
+		m.notifications.Add(1)
+		go func(notifier Notifier) {
+			defer m.notifications.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+			defer cancel()
+
+			if err := notifier.Notify(ctx, event); err != nil {
+				metrics.Notifications.WithLabelValues(notifier.Name(), metrics.NotificationFailed).Inc()
+				log.Printf("[MONITOR] ERREUR de notification '%s' pour le lien %s : %v", notifier.Name(), event.ShortCode, err)
+				return
+			}
+			metrics.Notifications.WithLabelValues(notifier.Name(), metrics.NotificationSent).Inc()
+		}(notifier)
+	}
+}
+
+// newEvent prépare un événement du moniteur à partir d'un lien et de sa dernière vérification.
+func newEvent(eventType string, link models.Link, check models.LinkCheck, message string) Event {
+	return Event{
+		Type:       eventType,
+		LinkID:     link.ID,
+		ShortCode:  link.ShortCode,
+		LongURL:    link.LongURL,
+		State:      eventState(check.Up),
+		StatusCode: check.StatusCode,
+		Result:     check.Result,
+		Error:      check.Error,
+		Message:    message,
+		OccurredAt: check.CheckedAt,
+	}
+}
+
+// eventState convertit un état d'accessibilité en état d'événement.
+func eventState(accessible bool) string {
+	if accessible {
+		return StateUp
+	}
+	return StateDown
 }
 
 // formatState est une fonction utilitaire pour rendre l'état plus lisible dans les logs.
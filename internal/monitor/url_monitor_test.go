@@ -0,0 +1,43 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+func TestForgetPreviousURL(t *testing.T) {
+	m := &UrlMonitor{
+		knownStates:  make(map[uint]bool),
+		knownURLs:    make(map[uint]string),
+		certWarnings: make(map[uint]time.Time),
+	}
+	link := models.Link{ID: 1, LongURL: "https://old.example.com"}
+
+	if m.forgetPreviousURL(link) {
+		t.Error("première vérification considérée comme un changement de destination")
+	}
+	m.knownStates[link.ID] = true
+	m.certWarnings[link.ID] = time.Now()
+	if m.forgetPreviousURL(link) {
+		t.Error("destination inchangée considérée comme remplacée")
+	}
+	if _, ok := m.knownStates[link.ID]; !ok {
+		t.Error("état connu oublié alors que la destination est inchangée")
+	}
+
+	link.LongURL = "https://new.example.com"
+	if !m.forgetPreviousURL(link) {
+		t.Fatal("changement de destination non détecté")
+	}
+	if _, ok := m.knownStates[link.ID]; ok {
+		t.Error("état de l'ancienne destination conservé")
+	}
+	if _, ok := m.certWarnings[link.ID]; ok {
+		t.Error("alerte de certificat de l'ancienne destination conservée")
+	}
+	if m.forgetPreviousURL(link) {
+		t.Error("nouvelle destination considérée comme remplacée à la vérification suivante")
+	}
+}
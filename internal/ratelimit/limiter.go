@@ -0,0 +1,107 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval est la fréquence à laquelle les seaux inactifs sont libérés.
+const sweepInterval = time.Minute
+
+// Result décrit la décision du limiteur pour une requête.
+type Result struct {
+	Allowed    bool          // La requête peut être traitée
+	Limit      int           // Capacité du seau (nombre de requêtes en rafale)
+	Remaining  int           // Jetons restants après cette requête
+	Reset      time.Duration // Délai avant que le seau soit de nouveau plein
+	RetryAfter time.Duration // Délai avant le prochain jeton (si la requête est refusée)
+}
+
+// bucket est le seau de jetons d'un client.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter applique un seau de jetons (token bucket) par client : chaque client dispose de
+// 'burst' jetons, rechargés au rythme de 'rate' jetons par seconde, et chaque requête en consomme un.
+type Limiter struct {
+	rate  float64 // Jetons ajoutés par seconde
+	burst float64 // Capacité maximale du seau
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewLimiter crée un limiteur autorisant requestsPerMinute requêtes par minute et par client,
+// avec des rafales d'au plus burst requêtes.
+func NewLimiter(requestsPerMinute, burst int) *Limiter {
+	if requestsPerMinute < 1 {
+		requestsPerMinute = 1
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{
+		rate:      float64(requestsPerMinute) / 60,
+		burst:     float64(burst),
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+// Allow consomme un jeton du seau associé à key si possible.
+func (l *Limiter) Allow(key string) Result {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	} else {
+		b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+		b.last = now
+	}
+
+	result := Result{Limit: int(l.burst)}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = l.timeToFill(1 - b.tokens)
+	}
+	result.Remaining = int(math.Floor(b.tokens))
+	result.Reset = l.timeToFill(l.burst - b.tokens)
+	return result
+}
+
+// timeToFill renvoie le temps nécessaire pour ajouter 'missing' jetons au seau.
+func (l *Limiter) timeToFill(missing float64) time.Duration {
+	if missing <= 0 {
+		return 0
+	}
+	return time.Duration(missing / l.rate * float64(time.Second))
+}
+
+// sweep libère les seaux qui se sont entièrement rechargés : ils sont équivalents à un seau neuf.
+// Le verrou doit être détenu.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"gorm.io/gorm"
+)
+
+// APIKeyRepository est une interface qui définit les méthodes d'accès aux données
+// pour les clés d'API.
+type APIKeyRepository interface {
+	CreateAPIKey(key *models.APIKey) error
+	GetAPIKeyByHash(keyHash string) (*models.APIKey, error)
+	ListAPIKeys() ([]models.APIKey, error)
+	RevokeAPIKey(id uint, at time.Time) error
+	TouchAPIKey(id uint, at time.Time) error
+}
+
+// GormAPIKeyRepository est l'implémentation de APIKeyRepository utilisant GORM.
+type GormAPIKeyRepository struct {
+	db *gorm.DB // Instance de la base de données GORM
+}
+
+// NewAPIKeyRepository crée et retourne une nouvelle instance de GormAPIKeyRepository.
+func NewAPIKeyRepository(db *gorm.DB) *GormAPIKeyRepository {
+	return &GormAPIKeyRepository{db: db}
+}
+
+// CreateAPIKey insère une nouvelle clé d'API dans la base de données.
+func (r *GormAPIKeyRepository) CreateAPIKey(key *models.APIKey) error {
+	result := r.db.Create(key)
+	if result.Error != nil {
+		return fmt.Errorf("erreur lors de la création de la clé d'API: %w", result.Error)
+	}
+	return nil
+}
+
+// GetAPIKeyByHash récupère une clé d'API via l'empreinte de son secret.
+// Il renvoie gorm.ErrRecordNotFound si aucune clé ne correspond.
+func (r *GormAPIKeyRepository) GetAPIKeyByHash(keyHash string) (*models.APIKey, error) {
+	var key models.APIKey
+	result := r.db.Where("key_hash = ?", keyHash).First(&key)
+	if result.Error != nil {
+		return nil, fmt.Errorf("erreur lors de la récupération de la clé d'API: %w", result.Error)
+	}
+	return &key, nil
+}
+
+// ListAPIKeys récupère toutes les clés d'API, révoquées comprises.
+func (r *GormAPIKeyRepository) ListAPIKeys() ([]models.APIKey, error) {
+	var keys []models.APIKey
+	result := r.db.Order("id").Find(&keys)
+	if result.Error != nil {
+		return nil, fmt.Errorf("erreur lors de la récupération des clés d'API: %w", result.Error)
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey marque une clé d'API comme révoquée.
+// Il renvoie gorm.ErrRecordNotFound si la clé n'existe pas.
+func (r *GormAPIKeyRepository) RevokeAPIKey(id uint, at time.Time) error {
+	result := r.db.Model(&models.APIKey{}).Where("id = ?", id).Update("revoked_at", at)
+	if result.Error != nil {
+		return fmt.Errorf("erreur lors de la révocation de la clé d'API: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("erreur lors de la révocation de la clé d'API: %w", gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+// TouchAPIKey met à jour la date de dernière utilisation d'une clé d'API.
+func (r *GormAPIKeyRepository) TouchAPIKey(id uint, at time.Time) error {
+	result := r.db.Model(&models.APIKey{}).Where("id = ?", id).UpdateColumn("last_used_at", at)
+	if result.Error != nil {
+		return fmt.Errorf("erreur lors de la mise à jour de la clé d'API: %w", result.Error)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/cache"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"gorm.io/gorm"
+)
+
+// cachedLink est une entrée du cache des liens. Found vaut false pour un code court inconnu (cache négatif).
+type cachedLink struct {
+	Link  models.Link
+	Found bool
+}
+
+// CachedLinkRepository place un cache LRU en mémoire devant un LinkRepository pour les recherches
+// par code court, qui sont sur le chemin critique des redirections.
+// Les codes inconnus sont aussi mis en cache (pour une durée plus courte) afin que des requêtes
+// répétées sur un code inexistant n'atteignent pas la base. Le cache est invalidé par les écritures
+// faites au travers de ce repository ; les modifications faites par un autre processus (commandes CLI)
+// ne sont visibles qu'à l'expiration des entrées.
+// Chaque invalidation incrémente une génération : une lecture commencée avant une écriture ne remet
+// pas en cache le lien qu'elle a lu, qui peut être antérieur à cette écriture.
+type CachedLinkRepository struct {
+	LinkRepository // Repository sous-jacent, utilisé directement pour les méthodes non mises en cache
+
+	cache       *cache.LRU[string, cachedLink]
+	ttl         time.Duration // Durée de vie d'un lien en cache
+	negativeTTL time.Duration // Durée de vie d'un code inconnu en cache
+
+	mu         sync.Mutex // Rend atomiques la vérification de la génération et la mise en cache
+	generation uint64     // Nombre d'invalidations effectuées (protégé par mu)
+}
+
+// NewCachedLinkRepository crée un repository avec un cache de taille maximale 'size' devant 'repo'.
+func NewCachedLinkRepository(repo LinkRepository, size int, ttl, negativeTTL time.Duration) *CachedLinkRepository {
+	return &CachedLinkRepository{
+		LinkRepository: repo,
+		cache:          cache.NewLRU[string, cachedLink](size),
+		ttl:            ttl,
+		negativeTTL:    negativeTTL,
+	}
+}
+
+// CacheStats renvoie les compteurs d'utilisation du cache.
+func (r *CachedLinkRepository) CacheStats() cache.Stats {
+	return r.cache.Stats()
+}
+
+// GetLinkByShortCode renvoie le lien depuis le cache, ou le lit dans le repository sous-jacent.
+// Comme ce dernier, il renvoie gorm.ErrRecordNotFound si aucun lien ne correspond au code court.
+func (r *CachedLinkRepository) GetLinkByShortCode(shortCode string) (*models.Link, error) {
+	if entry, ok := r.cache.Get(shortCode); ok {
+		if !entry.Found {
+			return nil, fmt.Errorf("erreur lors de la récupération du lien: %w", gorm.ErrRecordNotFound)
+		}
+		link := entry.Link // Copie : l'appelant peut modifier le lien sans altérer le cache
+		return &link, nil
+	}
+
+	generation := r.currentGeneration()
+	link, err := r.LinkRepository.GetLinkByShortCode(shortCode)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			r.setIfCurrent(generation, shortCode, cachedLink{}, r.negativeTTL)
+		}
+		return nil, err
+	}
+
+	r.setIfCurrent(generation, shortCode, cachedLink{Link: *link, Found: true}, r.ttl)
+	return link, nil
+}
+
+// CreateLink crée le lien et retire du cache un éventuel code inconnu mémorisé pour ce code court.
+func (r *CachedLinkRepository) CreateLink(link *models.Link) error {
+	err := r.LinkRepository.CreateLink(link)
+	r.invalidate(func() { r.cache.Delete(link.ShortCode) })
+	return err
+}
+
+// UpdateLink met à jour le lien et l'invalide dans le cache.
+func (r *CachedLinkRepository) UpdateLink(link *models.Link) error {
+	err := r.LinkRepository.UpdateLink(link)
+	r.invalidate(func() { r.cache.Delete(link.ShortCode) })
+	return err
+}
+
+// DeleteLink place le lien dans la corbeille et l'invalide dans le cache.
+func (r *CachedLinkRepository) DeleteLink(id uint) error {
+	err := r.LinkRepository.DeleteLink(id)
+	r.invalidateID(id)
+	return err
+}
+
+// PurgeLink supprime définitivement le lien et l'invalide dans le cache.
+func (r *CachedLinkRepository) PurgeLink(id uint) error {
+	err := r.LinkRepository.PurgeLink(id)
+	r.invalidateID(id)
+	return err
+}
+
+// RestoreLink sort le lien de la corbeille. Son code court peut être mémorisé comme inconnu
+// sans que l'on connaisse le lien correspondant : le cache est entièrement vidé.
+func (r *CachedLinkRepository) RestoreLink(id uint) error {
+	err := r.LinkRepository.RestoreLink(id)
+	r.invalidate(r.cache.Purge)
+	return err
+}
+
+// DeleteExpiredLinks place les liens expirés dans la corbeille et vide le cache si des liens ont été déplacés.
+func (r *CachedLinkRepository) DeleteExpiredLinks(before time.Time) (int64, error) {
+	deleted, err := r.LinkRepository.DeleteExpiredLinks(before)
+	if deleted > 0 {
+		r.invalidate(r.cache.Purge)
+	}
+	return deleted, err
+}
+
+// invalidateID retire du cache le lien portant l'identifiant id.
+func (r *CachedLinkRepository) invalidateID(id uint) {
+	r.invalidate(func() {
+		r.cache.DeleteFunc(func(_ string, entry cachedLink) bool {
+			return entry.Found && entry.Link.ID == id
+		})
+	})
+}
+
+// invalidate retire des entrées du cache après une écriture et passe à la génération suivante,
+// afin que les lectures commencées avant l'écriture ne remettent pas en cache un lien périmé.
+func (r *CachedLinkRepository) invalidate(remove func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.generation++
+	remove()
+}
+
+// currentGeneration renvoie la génération courante, relevée avant une lecture dans la base.
+func (r *CachedLinkRepository) currentGeneration() uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.generation
+}
+
+// setIfCurrent met une entrée en cache, sauf si une écriture a eu lieu depuis la génération relevée.
+func (r *CachedLinkRepository) setIfCurrent(generation uint64, shortCode string, entry cachedLink, ttl time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.generation == generation {
+		r.cache.Set(shortCode, entry, ttl)
+	}
+}
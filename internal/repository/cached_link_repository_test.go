@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// interleavedLinkRepository exécute 'during' pendant une lecture par code court, après que le lien
+// a été lu : cela reproduit une écriture concurrente qui termine avant que la lecture ne remplisse le cache.
+type interleavedLinkRepository struct {
+	LinkRepository
+	during func()
+}
+
+func (r *interleavedLinkRepository) GetLinkByShortCode(shortCode string) (*models.Link, error) {
+	link, err := r.LinkRepository.GetLinkByShortCode(shortCode)
+	if during := r.during; during != nil {
+		r.during = nil
+		during()
+	}
+	return link, err
+}
+
+func TestCachedLinkRepositoryDoesNotCacheStaleReads(t *testing.T) {
+	base := NewLinkRepository(openTestDB(t))
+	createTestLink(t, base, models.Link{ShortCode: "moved", LongURL: "https://old.example.com"})
+	interleaved := &interleavedLinkRepository{LinkRepository: base}
+	cached := NewCachedLinkRepository(interleaved, 10, time.Hour, time.Minute)
+
+	interleaved.during = func() {
+		link, err := base.GetLinkByShortCode("moved")
+		if err != nil {
+			t.Fatal(err)
+		}
+		link.LongURL = "https://new.example.com"
+		if err := cached.UpdateLink(link); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := cached.GetLinkByShortCode("moved"); err != nil {
+		t.Fatal(err)
+	}
+
+	link, err := cached.GetLinkByShortCode("moved")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.LongURL != "https://new.example.com" {
+		t.Errorf("destination périmée '%s' servie par le cache après la modification", link.LongURL)
+	}
+
+	// Sans écriture concurrente, la lecture suivante est bien servie par le cache
+	before := cached.CacheStats().Hits
+	if _, err := cached.GetLinkByShortCode("moved"); err != nil {
+		t.Fatal(err)
+	}
+	if cached.CacheStats().Hits != before+1 {
+		t.Error("lien non mis en cache en l'absence d'écriture concurrente")
+	}
+}
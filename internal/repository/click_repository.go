@@ -2,9 +2,11 @@ package repository
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/axellelanca/urlshortener/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // ClickRepository est une interface qui définit les méthodes d'accès aux données
@@ -12,7 +14,44 @@ import (
 // de rester indépendante de l'implémentation spécifique de la base de données.
 type ClickRepository interface {
 	CreateClick(click *models.Click) error
+	CreateClicks(clicks []models.Click) error
+	CreateSpooledClicks(clicks []models.Click) (int64, error)
 	CountClicksByLinkID(linkID uint) (int, error) // Utilisé par LinkService pour les stats
+	CountClicksByBucket(linkID uint, from, to time.Time, bucketOf func(time.Time) time.Time, includeBots bool) (map[time.Time]BucketCount, error)
+	CountUniqueVisitors(linkID uint, includeBots bool) (int, error)
+	DeleteClicksBefore(before time.Time) (int64, error)
+	AnonymizeClicksBefore(before time.Time) (int64, error)
+	DeleteClicksByIP(ips []string) (int64, error)
+	CountClicksByDimension(linkID uint, dimension string, limit int, includeBots bool) ([]DimensionCount, error)
+}
+
+// Dimensions de clic pouvant être utilisées pour une répartition des statistiques.
+const (
+	DimensionReferrer = "referrer"
+	DimensionBrowser  = "browser"
+	DimensionOS       = "os"
+	DimensionDevice   = "device"
+)
+
+// dimensionColumns associe chaque dimension autorisée à sa colonne dans la table 'clicks'.
+// Cette liste blanche évite toute injection via le nom de colonne.
+var dimensionColumns = map[string]string{
+	DimensionReferrer: "referrer",
+	DimensionBrowser:  "browser",
+	DimensionOS:       "os",
+	DimensionDevice:   "device_type",
+}
+
+// BucketCount représente les compteurs d'une tranche de temps.
+type BucketCount struct {
+	Clicks   int
+	Visitors int // Nombre d'empreintes de visiteurs distinctes dans la tranche
+}
+
+// DimensionCount représente le nombre de clics pour une valeur d'une dimension.
+type DimensionCount struct {
+	Value  string `json:"value"`
+	Clicks int    `json:"clicks"`
 }
 
 // GormClickRepository est l'implémentation de l'interface ClickRepository utilisant GORM.
@@ -36,6 +75,37 @@ func (r *GormClickRepository) CreateClick(click *models.Click) error {
 	return nil
 }
 
+// clickInsertBatchSize borne le nombre de lignes par requête INSERT lors des insertions groupées
+// (SQLite limite le nombre de paramètres par requête).
+const clickInsertBatchSize = 100
+
+// CreateClicks insère plusieurs clics en base en utilisant des requêtes INSERT groupées.
+// Au-delà d'un lot, les requêtes sont exécutées dans une même transaction : tous les clics sont enregistrés, ou aucun.
+func (r *GormClickRepository) CreateClicks(clicks []models.Click) error {
+	if len(clicks) == 0 {
+		return nil
+	}
+	result := r.db.CreateInBatches(clicks, clickInsertBatchSize)
+	if result.Error != nil {
+		return fmt.Errorf("erreur lors de la création des clics: %w", result.Error)
+	}
+	return nil
+}
+
+// CreateSpooledClicks insère des clics rejoués depuis le spool, comme CreateClicks, en ignorant
+// ceux dont le SpoolID est déjà enregistré : rejouer deux fois un même segment est sans effet.
+// Renvoie le nombre de clics réellement insérés.
+func (r *GormClickRepository) CreateSpooledClicks(clicks []models.Click) (int64, error) {
+	if len(clicks) == 0 {
+		return 0, nil
+	}
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(clicks, clickInsertBatchSize)
+	if result.Error != nil {
+		return 0, fmt.Errorf("erreur lors de la création des clics: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
 // CountClicksByLinkID compte le nombre total de clics pour un ID de lien donné.
 // Cette méthode est utilisée pour fournir des statistiques pour une URL courte.
 func (r *GormClickRepository) CountClicksByLinkID(linkID uint) (int, error) {
@@ -46,3 +116,146 @@ func (r *GormClickRepository) CountClicksByLinkID(linkID uint) (int, error) {
 	}
 	return int(count), nil
 }
+
+// scopeBots exclut les clics de robots d'une requête, sauf si includeBots est vrai.
+func scopeBots(query *gorm.DB, includeBots bool) *gorm.DB {
+	if includeBots {
+		return query
+	}
+	return query.Where("is_bot = ?", false)
+}
+
+// clickSlotSeconds est la durée des tranches calculées par la base pour CountClicksByBucket.
+// Un quart d'heure suffit pour les décalages en vigueur depuis 1980 dans la base des fuseaux horaires :
+// ils sont tous multiples de 15 minutes (les derniers à ne pas l'être, Africa/Monrovia et Pacific/Kiritimati,
+// ont changé en 1972 et 1979). Les décalages plus anciens, comme l'heure solaire locale (LMT) d'Europe/Amsterdam
+// jusqu'en 1937 (+00:19:32), ne le sont pas : un clic daté d'une telle période serait compté dans la tranche
+// où commence son quart d'heure UTC. Les clics étant horodatés à leur réception, le cas ne se présente pas.
+const clickSlotSeconds = 15 * 60
+
+// clickSlotExpr renvoie l'expression SQL du numéro de quart d'heure UTC d'un clic
+// (secondes depuis l'epoch divisées par clickSlotSeconds) pour le moteur donné.
+func clickSlotExpr(dialect string) string {
+	switch dialect {
+	case "postgres":
+		return fmt.Sprintf("CAST(FLOOR(EXTRACT(EPOCH FROM clicks.timestamp) / %d) AS BIGINT)", clickSlotSeconds)
+	case "mysql":
+		// Contrairement à UNIX_TIMESTAMP, TIMESTAMPDIFF ne dépend pas du fuseau horaire de la session
+		return fmt.Sprintf("TIMESTAMPDIFF(SECOND, '1970-01-01 00:00:00', clicks.timestamp) DIV %d", clickSlotSeconds)
+	default:
+		return fmt.Sprintf("CAST(strftime('%%s', clicks.timestamp) AS INTEGER) / %d", clickSlotSeconds)
+	}
+}
+
+// CountClicksByBucket agrège les clics d'un lien sur l'intervalle [from, to) en les regroupant
+// par tranche de temps. bucketOf associe l'horodatage d'un clic au début de sa tranche.
+// La base regroupe les clics par quart d'heure UTC et par visiteur ; ces groupes sont ensuite
+// répartis côté Go dans les tranches du fuseau horaire demandé, quel que soit le moteur de base de données.
+// Le volume lu est ainsi borné par le nombre de couples (quart d'heure, visiteur), et non par le nombre de clics.
+// Seules les tranches contenant au moins un clic sont renvoyées.
+func (r *GormClickRepository) CountClicksByBucket(linkID uint, from, to time.Time, bucketOf func(time.Time) time.Time, includeBots bool) (map[time.Time]BucketCount, error) {
+	// "timestamp" est un mot-clé SQL : la colonne est qualifiée par sa table pour rester portable entre les moteurs
+	query := r.db.Model(&models.Click{}).
+		Select(clickSlotExpr(r.db.Dialector.Name())+" AS slot, visitor_hash, COUNT(*) AS clicks").
+		Where("link_id = ? AND clicks.timestamp >= ? AND clicks.timestamp < ?", linkID, from.UTC(), to.UTC()).
+		Group("slot, visitor_hash")
+	var slots []struct {
+		Slot        int64
+		VisitorHash *string
+		Clicks      int
+	}
+	if err := scopeBots(query, includeBots).Scan(&slots).Error; err != nil {
+		return nil, fmt.Errorf("erreur lors de l'agrégation des clics: %w", err)
+	}
+
+	counts := make(map[time.Time]BucketCount)
+	visitors := make(map[time.Time]map[string]struct{})
+	for _, slot := range slots {
+		bucket := bucketOf(time.Unix(slot.Slot*clickSlotSeconds, 0).UTC())
+		count := counts[bucket]
+		count.Clicks += slot.Clicks
+		if slot.VisitorHash != nil && *slot.VisitorHash != "" {
+			// Un même visiteur peut apparaître dans plusieurs quarts d'heure d'une tranche
+			if visitors[bucket] == nil {
+				visitors[bucket] = make(map[string]struct{})
+			}
+			if _, seen := visitors[bucket][*slot.VisitorHash]; !seen {
+				visitors[bucket][*slot.VisitorHash] = struct{}{}
+				count.Visitors++
+			}
+		}
+		counts[bucket] = count
+	}
+	return counts, nil
+}
+
+// CountClicksByDimension répartit les clics d'un lien selon une dimension (ex: referrer),
+// triés par nombre de clics décroissant. limit borne le nombre de valeurs renvoyées (0 = toutes).
+func (r *GormClickRepository) CountClicksByDimension(linkID uint, dimension string, limit int, includeBots bool) ([]DimensionCount, error) {
+	column, ok := dimensionColumns[dimension]
+	if !ok {
+		return nil, fmt.Errorf("dimension de clic inconnue: %s", dimension)
+	}
+
+	query := scopeBots(r.db.Model(&models.Click{}), includeBots).
+		Select(column+" AS value, COUNT(*) AS clicks").
+		Where("link_id = ?", linkID).
+		Group(column).
+		Order("clicks DESC, value")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var counts []DimensionCount
+	if err := query.Scan(&counts).Error; err != nil {
+		return nil, fmt.Errorf("erreur lors de la répartition des clics par %s: %w", dimension, err)
+	}
+	return counts, nil
+}
+
+// CountUniqueVisitors compte les empreintes de visiteurs distinctes d'un lien.
+// Le sel des empreintes changeant chaque jour, un visiteur revenu plusieurs jours est compté une fois par jour.
+func (r *GormClickRepository) CountUniqueVisitors(linkID uint, includeBots bool) (int, error) {
+	var count int64
+	query := scopeBots(r.db.Model(&models.Click{}), includeBots).
+		Where("link_id = ? AND visitor_hash IS NOT NULL AND visitor_hash <> ''", linkID).
+		Distinct("visitor_hash")
+	if err := query.Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("erreur lors du comptage des visiteurs uniques: %w", err)
+	}
+	return int(count), nil
+}
+
+// DeleteClicksBefore supprime définitivement les clics antérieurs à 'before'.
+// Renvoie le nombre de clics supprimés.
+func (r *GormClickRepository) DeleteClicksBefore(before time.Time) (int64, error) {
+	result := r.db.Where("clicks.timestamp < ?", before.UTC()).Delete(&models.Click{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("erreur lors de la suppression des anciens clics: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// AnonymizeClicksBefore efface les données personnelles (IP, User-Agent brut, empreinte du visiteur)
+// des clics antérieurs à 'before'. Les dimensions agrégées (date, referrer, navigateur, système,
+// appareil) sont conservées afin que les statistiques restent disponibles.
+// Renvoie le nombre de clics anonymisés.
+func (r *GormClickRepository) AnonymizeClicksBefore(before time.Time) (int64, error) {
+	result := r.db.Model(&models.Click{}).
+		Where("clicks.timestamp < ? AND (ip_address <> '' OR user_agent <> '' OR visitor_hash <> '')", before.UTC()).
+		Updates(map[string]interface{}{"ip_address": "", "user_agent": "", "visitor_hash": ""})
+	if result.Error != nil {
+		return 0, fmt.Errorf("erreur lors de l'anonymisation des anciens clics: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// DeleteClicksByIP supprime tous les clics enregistrés avec l'une des adresses IP fournies.
+// Renvoie le nombre de clics supprimés.
+func (r *GormClickRepository) DeleteClicksByIP(ips []string) (int64, error) {
+	result := r.db.Where("ip_address IN ?", ips).Delete(&models.Click{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("erreur lors de la suppression des clics par IP: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,227 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// seedClicks crée un lien et ses clics, puis renvoie le dépôt de clics et l'ID du lien.
+func seedClicks(t *testing.T, clicks []models.Click) (*GormClickRepository, uint) {
+	t.Helper()
+	db := openTestDB(t)
+	link := createTestLink(t, NewLinkRepository(db), models.Link{ShortCode: "stats", LongURL: "https://example.com"})
+
+	repo := NewClickRepository(db)
+	for i := range clicks {
+		clicks[i].LinkID = link.ID
+	}
+	if err := repo.CreateClicks(clicks); err != nil {
+		t.Fatalf("création des clics: %v", err)
+	}
+	return repo, link.ID
+}
+
+// at renvoie l'instant UTC du jour day d'octobre 2026 à hour:minute.
+func at(day, hour, minute int) time.Time {
+	return time.Date(2026, time.October, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestCountClicksByBucket(t *testing.T) {
+	repo, linkID := seedClicks(t, []models.Click{
+		{Timestamp: at(14, 9, 5), VisitorHash: "a"},
+		{Timestamp: at(14, 9, 50), VisitorHash: "a"},
+		{Timestamp: at(14, 18, 40), VisitorHash: "b"}, // 00:10 le 15 à Calcutta (UTC+5:30)
+		{Timestamp: at(14, 18, 20), VisitorHash: "c"}, // 23:50 le 14 à Calcutta
+		{Timestamp: at(14, 10, 0), VisitorHash: "bot", IsBot: true},
+		{Timestamp: at(15, 1, 0)},                      // Sans empreinte : compté comme clic uniquement
+		{Timestamp: at(16, 0, 0), VisitorHash: "late"}, // Hors intervalle
+	})
+	from, to := at(14, 0, 0), at(16, 0, 0)
+
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Skipf("base des fuseaux horaires indisponible: %v", err)
+	}
+	dayIn := func(loc *time.Location) func(time.Time) time.Time {
+		return func(ts time.Time) time.Time {
+			ts = ts.In(loc)
+			return time.Date(ts.Year(), ts.Month(), ts.Day(), 0, 0, 0, 0, loc)
+		}
+	}
+	hour := func(ts time.Time) time.Time { return ts.Truncate(time.Hour) }
+
+	tests := []struct {
+		name        string
+		bucketOf    func(time.Time) time.Time
+		includeBots bool
+		want        map[time.Time]BucketCount
+	}{
+		{"par heure", hour, false, map[time.Time]BucketCount{
+			at(14, 9, 0):  {Clicks: 2, Visitors: 1},
+			at(14, 18, 0): {Clicks: 2, Visitors: 2},
+			at(15, 1, 0):  {Clicks: 1},
+		}},
+		{"par jour UTC avec les robots", dayIn(time.UTC), true, map[time.Time]BucketCount{
+			at(14, 0, 0): {Clicks: 5, Visitors: 4},
+			at(15, 0, 0): {Clicks: 1},
+		}},
+		{"par jour à Calcutta", dayIn(kolkata), false, map[time.Time]BucketCount{
+			time.Date(2026, time.October, 14, 0, 0, 0, 0, kolkata): {Clicks: 3, Visitors: 2},
+			time.Date(2026, time.October, 15, 0, 0, 0, 0, kolkata): {Clicks: 2, Visitors: 1},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counts, err := repo.CountClicksByBucket(linkID, from, to, tt.bucketOf, tt.includeBots)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(counts) != len(tt.want) {
+				t.Fatalf("CountClicksByBucket = %v, attendu %v", counts, tt.want)
+			}
+			for bucket, want := range tt.want {
+				if got, ok := counts[bucket]; !ok || got != want {
+					t.Errorf("tranche %v: %+v, attendu %+v", bucket, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCountClicksByDimension(t *testing.T) {
+	repo, linkID := seedClicks(t, []models.Click{
+		{Timestamp: at(14, 9, 0), Browser: "Firefox", Referrer: "example.org"},
+		{Timestamp: at(14, 9, 0), Browser: "Chrome", Referrer: "example.org"},
+		{Timestamp: at(14, 9, 0), Browser: "Firefox"},
+		{Timestamp: at(14, 9, 0), Browser: "Firefox", Referrer: "news.example"},
+		{Timestamp: at(14, 9, 0), Browser: "Googlebot", IsBot: true},
+	})
+
+	tests := []struct {
+		name        string
+		dimension   string
+		limit       int
+		includeBots bool
+		want        []DimensionCount
+	}{
+		{"navigateurs", DimensionBrowser, 0, false, []DimensionCount{{"Firefox", 3}, {"Chrome", 1}}},
+		{"navigateurs avec les robots", DimensionBrowser, 0, true, []DimensionCount{{"Firefox", 3}, {"Chrome", 1}, {"Googlebot", 1}}},
+		{"referrers limités", DimensionReferrer, 2, false, []DimensionCount{{"example.org", 2}, {"", 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counts, err := repo.CountClicksByDimension(linkID, tt.dimension, tt.limit, tt.includeBots)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(counts) != len(tt.want) {
+				t.Fatalf("CountClicksByDimension = %v, attendu %v", counts, tt.want)
+			}
+			for i := range counts {
+				if counts[i] != tt.want[i] {
+					t.Fatalf("CountClicksByDimension = %v, attendu %v", counts, tt.want)
+				}
+			}
+		})
+	}
+
+	if _, err := repo.CountClicksByDimension(linkID, "ip_address", 0, false); err == nil {
+		t.Error("dimension hors liste blanche acceptée")
+	}
+}
+
+func TestCountUniqueVisitors(t *testing.T) {
+	repo, linkID := seedClicks(t, []models.Click{
+		{Timestamp: at(14, 9, 0), VisitorHash: "a"},
+		{Timestamp: at(14, 10, 0), VisitorHash: "a"},
+		{Timestamp: at(14, 11, 0), VisitorHash: "b"},
+		{Timestamp: at(14, 12, 0)},
+		{Timestamp: at(14, 13, 0), VisitorHash: "bot", IsBot: true},
+	})
+
+	for includeBots, want := range map[bool]int{false: 2, true: 3} {
+		count, err := repo.CountUniqueVisitors(linkID, includeBots)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != want {
+			t.Errorf("CountUniqueVisitors(includeBots=%v) = %d, attendu %d", includeBots, count, want)
+		}
+	}
+}
+
+func TestDeleteClicksBefore(t *testing.T) {
+	repo, linkID := seedClicks(t, []models.Click{
+		{Timestamp: at(10, 0, 0)},
+		{Timestamp: at(11, 0, 0)},
+		{Timestamp: at(14, 0, 0)},
+	})
+
+	deleted, err := repo.DeleteClicksBefore(at(12, 0, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	count, err := repo.CountClicksByLinkID(linkID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 2 || count != 1 {
+		t.Errorf("%d clic(s) supprimé(s), %d restant(s) ; attendu 2 et 1", deleted, count)
+	}
+}
+
+func TestAnonymizeClicksBefore(t *testing.T) {
+	repo, linkID := seedClicks(t, []models.Click{
+		{Timestamp: at(10, 0, 0), IPAddress: "203.0.113.1", UserAgent: "Firefox", VisitorHash: "a", Browser: "Firefox"},
+		{Timestamp: at(14, 0, 0), IPAddress: "203.0.113.2", UserAgent: "Chrome", VisitorHash: "b", Browser: "Chrome"},
+	})
+
+	anonymized, err := repo.AnonymizeClicksBefore(at(12, 0, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if anonymized != 1 {
+		t.Errorf("%d clic(s) anonymisé(s), 1 attendu", anonymized)
+	}
+
+	// Un second passage ne retouche pas les clics déjà anonymisés
+	if again, err := repo.AnonymizeClicksBefore(at(12, 0, 0)); err != nil || again != 0 {
+		t.Errorf("second passage: %d clic(s), %v", again, err)
+	}
+
+	var clicks []models.Click
+	if err := repo.db.Where("link_id = ?", linkID).Order("id").Find(&clicks).Error; err != nil {
+		t.Fatal(err)
+	}
+	old, recent := clicks[0], clicks[1]
+	if old.IPAddress != "" || old.UserAgent != "" || old.VisitorHash != "" || old.Browser != "Firefox" {
+		t.Errorf("ancien clic mal anonymisé: %+v", old)
+	}
+	if recent.IPAddress != "203.0.113.2" || recent.VisitorHash != "b" {
+		t.Errorf("clic récent modifié: %+v", recent)
+	}
+}
+
+func TestCreateSpooledClicksSkipsReplayedEvents(t *testing.T) {
+	repo, linkID := seedClicks(t, nil)
+	id1, id2 := "0001", "0002"
+
+	first := []models.Click{{LinkID: linkID, Timestamp: at(14, 9, 0), SpoolID: &id1}}
+	if inserted, err := repo.CreateSpooledClicks(first); err != nil || inserted != 1 {
+		t.Fatalf("premier rejeu: %d clic(s), %v", inserted, err)
+	}
+
+	replayed := []models.Click{
+		{LinkID: linkID, Timestamp: at(14, 9, 0), SpoolID: &id1},
+		{LinkID: linkID, Timestamp: at(14, 9, 0), SpoolID: &id2},
+	}
+	inserted, err := repo.CreateSpooledClicks(replayed)
+	if err != nil || inserted != 1 {
+		t.Fatalf("second rejeu: %d clic(s), %v ; 1 attendu", inserted, err)
+	}
+	if count, _ := repo.CountClicksByLinkID(linkID); count != 2 {
+		t.Errorf("%d clic(s) enregistré(s), 2 attendus", count)
+	}
+}
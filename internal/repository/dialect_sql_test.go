@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// sqlRecorder est un logger GORM qui conserve les requêtes générées au lieu de les journaliser.
+type sqlRecorder struct {
+	logger.Interface
+	statements []string
+}
+
+func (r *sqlRecorder) LogMode(logger.LogLevel) logger.Interface {
+	return r
+}
+
+func (r *sqlRecorder) Trace(_ context.Context, _ time.Time, fc func() (string, int64), _ error) {
+	sql, _ := fc()
+	r.statements = append(r.statements, sql)
+}
+
+// last renvoie la dernière requête générée et vide l'historique.
+func (r *sqlRecorder) last(t *testing.T) string {
+	t.Helper()
+	if len(r.statements) == 0 {
+		t.Fatal("aucune requête générée")
+	}
+	sql := r.statements[len(r.statements)-1]
+	r.statements = nil
+	return sql
+}
+
+// dryRunDialectors associe PostgreSQL et MySQL à un dialecte GORM qui ne se connecte jamais :
+// en mode DryRun, les requêtes sont générées puis abandonnées, ce qui permet de vérifier le SQL
+// propre à ces moteurs sans serveur.
+var dryRunDialectors = map[string]gorm.Dialector{
+	"postgres": postgres.New(postgres.Config{DSN: "host=localhost user=urlshortener dbname=urlshortener sslmode=disable"}),
+	"mysql":    mysql.New(mysql.Config{DSN: "urlshortener:secret@tcp(localhost:3306)/urlshortener?parseTime=true", SkipInitializeWithVersion: true}),
+}
+
+// openDryRunDB ouvre une session DryRun pour le dialecte donné et renvoie l'enregistreur de ses requêtes.
+func openDryRunDB(t *testing.T, dialector gorm.Dialector) (*gorm.DB, *sqlRecorder) {
+	t.Helper()
+	recorder := &sqlRecorder{Interface: logger.Discard}
+	db, err := gorm.Open(dialector, &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true, Logger: recorder})
+	if err != nil {
+		t.Fatalf("ouverture du dialecte %s: %v", dialector.Name(), err)
+	}
+	return db, recorder
+}
+
+// dialectSQL associe un moteur au SQL attendu pour une requête.
+type dialectSQL struct {
+	dialect string
+	want    string
+}
+
+func TestCountClicksByBucketSQL(t *testing.T) {
+	from := time.Date(2026, time.October, 14, 0, 0, 0, 0, time.UTC)
+	tests := []dialectSQL{
+		{"postgres", `SELECT CAST(FLOOR(EXTRACT(EPOCH FROM clicks.timestamp) / 900) AS BIGINT) AS slot, visitor_hash, COUNT(*) AS clicks ` +
+			`FROM "clicks" WHERE (link_id = 1 AND clicks.timestamp >= '2026-10-14 00:00:00' AND clicks.timestamp < '2026-10-15 00:00:00') ` +
+			`AND is_bot = false GROUP BY slot, visitor_hash`},
+		{"mysql", "SELECT TIMESTAMPDIFF(SECOND, '1970-01-01 00:00:00', clicks.timestamp) DIV 900 AS slot, visitor_hash, COUNT(*) AS clicks " +
+			"FROM `clicks` WHERE (link_id = 1 AND clicks.timestamp >= '2026-10-14 00:00:00' AND clicks.timestamp < '2026-10-15 00:00:00') " +
+			"AND is_bot = false GROUP BY slot, visitor_hash"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dialect, func(t *testing.T) {
+			db, recorder := openDryRunDB(t, dryRunDialectors[tt.dialect])
+			// En mode DryRun, la lecture du résultat échoue : seule la requête générée est vérifiée
+			NewClickRepository(db).CountClicksByBucket(1, from, from.Add(24*time.Hour), func(ts time.Time) time.Time { return ts }, false)
+			if got := recorder.last(t); got != tt.want {
+				t.Errorf("requête générée:\n%s\nattendue:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListLinksSearchSQL(t *testing.T) {
+	tests := []dialectSQL{
+		{"postgres", `SELECT count(*) FROM "links" WHERE (LOWER(short_code) LIKE '%docs!_v1%' ESCAPE '!' OR LOWER(long_url) LIKE '%docs!_v1%' ESCAPE '!') ` +
+			`AND "links"."deleted_at" IS NULL`},
+		{"mysql", "SELECT count(*) FROM `links` WHERE (LOWER(short_code) LIKE '%docs!_v1%' ESCAPE '!' OR LOWER(long_url) LIKE '%docs!_v1%' ESCAPE '!') " +
+			"AND `links`.`deleted_at` IS NULL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dialect, func(t *testing.T) {
+			db, recorder := openDryRunDB(t, dryRunDialectors[tt.dialect])
+			NewLinkRepository(db).ListLinks(LinkFilter{Query: "Docs_v1"})
+			if got := recorder.last(t); got != tt.want {
+				t.Errorf("requête générée:\n%s\nattendue:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateSpooledClicksSQL(t *testing.T) {
+	tests := []dialectSQL{
+		{"postgres", `INSERT INTO "clicks" ("link_id","timestamp","user_agent","ip_address","referrer","browser","browser_version","os","device_type","is_bot","visitor_hash","spool_id") ` +
+			`VALUES (1,'2026-10-14 09:00:00','','','','','','','',false,'','0001') ON CONFLICT DO NOTHING RETURNING "id"`},
+		{"mysql", "INSERT INTO `clicks` (`link_id`,`timestamp`,`user_agent`,`ip_address`,`referrer`,`browser`,`browser_version`,`os`,`device_type`,`is_bot`,`visitor_hash`,`spool_id`) " +
+			"VALUES (1,'2026-10-14 09:00:00','','','','','','','',false,'','0001') ON DUPLICATE KEY UPDATE `id`=`id`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dialect, func(t *testing.T) {
+			db, recorder := openDryRunDB(t, dryRunDialectors[tt.dialect])
+			id := "0001"
+			NewClickRepository(db).CreateSpooledClicks([]models.Click{{LinkID: 1, Timestamp: time.Date(2026, time.October, 14, 9, 0, 0, 0, time.UTC), SpoolID: &id}})
+			if got := recorder.last(t); got != tt.want {
+				t.Errorf("requête générée:\n%s\nattendue:\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"gorm.io/gorm"
+)
+
+// LinkCheckRepository définit les méthodes d'accès à l'historique des vérifications du moniteur.
+type LinkCheckRepository interface {
+	RecordCheck(check *models.LinkCheck) error
+	GetRecentChecks(linkID uint, limit int) ([]models.LinkCheck, error)
+	CountChecksSince(linkID uint, since time.Time) (total int64, up int64, err error)
+	DeleteChecksBefore(before time.Time) (int64, error)
+}
+
+// GormLinkCheckRepository est l'implémentation de l'interface LinkCheckRepository utilisant GORM.
+type GormLinkCheckRepository struct {
+	db *gorm.DB
+}
+
+// NewLinkCheckRepository crée et retourne une nouvelle instance de GormLinkCheckRepository.
+func NewLinkCheckRepository(db *gorm.DB) *GormLinkCheckRepository {
+	return &GormLinkCheckRepository{db: db}
+}
+
+// RecordCheck enregistre le résultat d'une vérification et met à jour, dans la même transaction,
+// le dernier état connu du lien (et sa dernière empreinte de contenu, si la vérification en a calculé une).
+func (r *GormLinkCheckRepository) RecordCheck(check *models.LinkCheck) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(check).Error; err != nil {
+			return err
+		}
+		updates := map[string]interface{}{"last_check_up": check.Up, "last_checked_at": check.CheckedAt}
+		if check.ContentFingerprint != "" {
+			updates["content_fingerprint"] = check.ContentFingerprint
+		}
+		return tx.Model(&models.Link{}).Where("id = ?", check.LinkID).Updates(updates).Error
+	})
+	if err != nil {
+		return fmt.Errorf("erreur lors de l'enregistrement de la vérification: %w", err)
+	}
+	return nil
+}
+
+// GetRecentChecks renvoie les 'limit' vérifications les plus récentes d'un lien, de la plus récente à la plus ancienne.
+func (r *GormLinkCheckRepository) GetRecentChecks(linkID uint, limit int) ([]models.LinkCheck, error) {
+	var checks []models.LinkCheck
+	err := r.db.Where("link_id = ?", linkID).Order("checked_at DESC, id DESC").Limit(limit).Find(&checks).Error
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors de la récupération des vérifications: %w", err)
+	}
+	return checks, nil
+}
+
+// CountChecksSince compte les vérifications d'un lien effectuées depuis 'since', dont celles
+// pour lesquelles la destination était accessible.
+func (r *GormLinkCheckRepository) CountChecksSince(linkID uint, since time.Time) (int64, int64, error) {
+	var total, up int64
+	query := r.db.Model(&models.LinkCheck{}).Where("link_id = ? AND checked_at >= ?", linkID, since)
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return 0, 0, fmt.Errorf("erreur lors du comptage des vérifications: %w", err)
+	}
+	if err := query.Session(&gorm.Session{}).Where("up = ?", true).Count(&up).Error; err != nil {
+		return 0, 0, fmt.Errorf("erreur lors du comptage des vérifications: %w", err)
+	}
+	return total, up, nil
+}
+
+// DeleteChecksBefore supprime les vérifications antérieures à 'before' et renvoie leur nombre.
+func (r *GormLinkCheckRepository) DeleteChecksBefore(before time.Time) (int64, error) {
+	result := r.db.Where("checked_at < ?", before).Delete(&models.LinkCheck{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("erreur lors de la suppression des anciennes vérifications: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/axellelanca/urlshortener/internal/models"
 	"gorm.io/gorm"
@@ -14,6 +16,40 @@ type LinkRepository interface {
 	GetLinkByShortCode(shortCode string) (*models.Link, error)
 	GetAllLinks() ([]models.Link, error)
 	CountClicksByLinkID(linkID uint) (int, error)
+	CountBotClicksByLinkID(linkID uint) (int, error)
+	DeleteExpiredLinks(before time.Time) (int64, error)
+	ListLinks(filter LinkFilter) ([]models.Link, int64, error)
+	UpdateLink(link *models.Link) error
+	DeleteLink(id uint) error
+	GetTrashedLinkByShortCode(shortCode string) (*models.Link, error)
+	ListTrashedLinks(ownerKeyID *uint) ([]models.Link, error)
+	RestoreLink(id uint) error
+	PurgeLink(id uint) error
+	PurgeTrashedLinks(deletedBefore time.Time, ownerKeyID *uint) (int64, error)
+}
+
+// Valeurs possibles pour LinkFilter.Status.
+const (
+	LinkStatusActive  = "active"
+	LinkStatusExpired = "expired"
+)
+
+// LinkFilter regroupe les critères de filtrage et de pagination pour ListLinks.
+type LinkFilter struct {
+	Query  string // Recherche partielle sur le code court ou l'URL longue
+	Status string // "active", "expired" ou vide pour tous les liens
+	Limit  int    // Nombre maximum de liens renvoyés (0 = pas de limite)
+	Offset int    // Nombre de liens à ignorer (pagination)
+
+	OwnerKeyID *uint // Restreint la liste aux liens d'une clé d'API (nil = tous les liens)
+}
+
+// scopeOwner restreint une requête aux liens d'une clé d'API lorsque ownerKeyID est renseigné.
+func scopeOwner(query *gorm.DB, ownerKeyID *uint) *gorm.DB {
+	if ownerKeyID == nil {
+		return query
+	}
+	return query.Where("owner_key_id = ?", *ownerKeyID)
 }
 
 // GormLinkRepository est l'implémentation de LinkRepository utilisant GORM.
@@ -69,3 +105,190 @@ func (r *GormLinkRepository) CountClicksByLinkID(linkID uint) (int, error) {
 	}
 	return int(count), nil
 }
+
+// CountBotClicksByLinkID compte les clics attribués à des robots pour un ID de lien donné.
+func (r *GormLinkRepository) CountBotClicksByLinkID(linkID uint) (int, error) {
+	var count int64
+	result := r.db.Model(&models.Click{}).Where("link_id = ? AND is_bot = ?", linkID, true).Count(&count)
+	if result.Error != nil {
+		return 0, fmt.Errorf("erreur lors du comptage des clics de robots: %w", result.Error)
+	}
+	return int(count), nil
+}
+
+// DeleteExpiredLinks place dans la corbeille les liens dont la date d'expiration est
+// antérieure à 'before'. Leurs clics sont conservés jusqu'à la purge de la corbeille.
+// Renvoie le nombre de liens déplacés.
+func (r *GormLinkRepository) DeleteExpiredLinks(before time.Time) (int64, error) {
+	result := r.db.Where("expires_at IS NOT NULL AND expires_at < ?", before).Delete(&models.Link{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("erreur lors de la suppression des liens expirés: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// ListLinks récupère une page de liens correspondant au filtre, triés du plus récent au plus ancien.
+// Elle renvoie également le nombre total de liens correspondants (hors pagination).
+func (r *GormLinkRepository) ListLinks(filter LinkFilter) ([]models.Link, int64, error) {
+	query := scopeOwner(r.db.Model(&models.Link{}), filter.OwnerKeyID)
+
+	if filter.Query != "" {
+		// LOWER() rend la recherche insensible à la casse sur tous les moteurs (LIKE l'est déjà sous SQLite et MySQL, pas sous PostgreSQL)
+		pattern := "%" + escapeLike(strings.ToLower(filter.Query)) + "%"
+		query = query.Where("LOWER(short_code) LIKE ? ESCAPE '!' OR LOWER(long_url) LIKE ? ESCAPE '!'", pattern, pattern)
+	}
+
+	now := time.Now()
+	switch filter.Status {
+	case LinkStatusActive:
+		query = query.Where("expires_at IS NULL OR expires_at > ?", now)
+	case LinkStatusExpired:
+		query = query.Where("expires_at IS NOT NULL AND expires_at <= ?", now)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("erreur lors du comptage des liens: %w", err)
+	}
+
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+
+	var links []models.Link
+	if err := query.Order("created_at DESC, id DESC").Find(&links).Error; err != nil {
+		return nil, 0, fmt.Errorf("erreur lors de la récupération des liens: %w", err)
+	}
+	return links, total, nil
+}
+
+// likeEscaper neutralise les jokers de LIKE ('%', '_') et le caractère d'échappement lui-même.
+// Le caractère d'échappement est '!' plutôt que '\', qui s'écrit différemment dans les chaînes SQL
+// de MySQL et de PostgreSQL.
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// escapeLike échappe une chaîne recherchée telle quelle dans un motif LIKE ... ESCAPE '!'.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// UpdateLink enregistre les modifications d'un lien existant.
+// Le dernier état connu de la destination est tenu à jour par le moniteur et n'est pas réécrit ici,
+// sauf si l'URL de destination change : il concerne alors l'ancienne destination et est effacé.
+func (r *GormLinkRepository) UpdateLink(link *models.Link) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		reset := tx.Model(&models.Link{}).Where("id = ? AND long_url <> ?", link.ID, link.LongURL).
+			Updates(map[string]interface{}{"last_check_up": nil, "last_checked_at": nil, "content_fingerprint": ""})
+		if reset.Error != nil {
+			return reset.Error
+		}
+		if reset.RowsAffected > 0 {
+			link.LastCheckUp, link.LastCheckedAt, link.ContentFingerprint = nil, nil, ""
+		}
+		return tx.Omit("LastCheckUp", "LastCheckedAt", "ContentFingerprint").Save(link).Error
+	})
+	if err != nil {
+		return fmt.Errorf("erreur lors de la mise à jour du lien: %w", err)
+	}
+	return nil
+}
+
+// DeleteLink place un lien dans la corbeille (suppression logique).
+// Ses clics sont conservés afin de pouvoir le restaurer.
+func (r *GormLinkRepository) DeleteLink(id uint) error {
+	result := r.db.Delete(&models.Link{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("erreur lors de la suppression du lien: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("erreur lors de la suppression du lien: %w", gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+// GetTrashedLinkByShortCode récupère un lien présent dans la corbeille via son shortCode.
+// Il renvoie gorm.ErrRecordNotFound si aucun lien supprimé ne correspond.
+func (r *GormLinkRepository) GetTrashedLinkByShortCode(shortCode string) (*models.Link, error) {
+	var link models.Link
+	result := r.db.Unscoped().Where("short_code = ? AND deleted_at IS NOT NULL", shortCode).First(&link)
+	if result.Error != nil {
+		return nil, fmt.Errorf("erreur lors de la récupération du lien supprimé: %w", result.Error)
+	}
+	return &link, nil
+}
+
+// ListTrashedLinks récupère les liens présents dans la corbeille, du plus récemment supprimé au plus ancien.
+// Si ownerKeyID est renseigné, seuls les liens de cette clé d'API sont renvoyés.
+func (r *GormLinkRepository) ListTrashedLinks(ownerKeyID *uint) ([]models.Link, error) {
+	var links []models.Link
+	query := scopeOwner(r.db.Unscoped().Where("deleted_at IS NOT NULL"), ownerKeyID)
+	result := query.Order("deleted_at DESC").Find(&links)
+	if result.Error != nil {
+		return nil, fmt.Errorf("erreur lors de la récupération de la corbeille: %w", result.Error)
+	}
+	return links, nil
+}
+
+// RestoreLink sort un lien de la corbeille.
+// Il renvoie gorm.ErrRecordNotFound si le lien n'existe pas ou n'est pas dans la corbeille.
+func (r *GormLinkRepository) RestoreLink(id uint) error {
+	result := r.db.Unscoped().Model(&models.Link{}).Where("id = ? AND deleted_at IS NOT NULL", id).Update("deleted_at", nil)
+	if result.Error != nil {
+		return fmt.Errorf("erreur lors de la restauration du lien: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("erreur lors de la restauration du lien: %w", gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+// PurgeLink supprime définitivement un lien, ses clics et l'historique de ses vérifications.
+func (r *GormLinkRepository) PurgeLink(id uint) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("link_id = ?", id).Delete(&models.Click{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("link_id = ?", id).Delete(&models.LinkCheck{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&models.Link{}, id).Error
+	})
+	if err != nil {
+		return fmt.Errorf("erreur lors de la purge du lien: %w", err)
+	}
+	return nil
+}
+
+// PurgeTrashedLinks supprime définitivement les liens placés dans la corbeille avant 'deletedBefore',
+// ainsi que leurs clics et l'historique de leurs vérifications.
+// Si ownerKeyID est renseigné, seuls les liens de cette clé d'API sont purgés.
+// Renvoie le nombre de liens purgés.
+func (r *GormLinkRepository) PurgeTrashedLinks(deletedBefore time.Time, ownerKeyID *uint) (int64, error) {
+	var purged int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		trashed := scopeOwner(tx.Unscoped().Model(&models.Link{}).Select("id").
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", deletedBefore), ownerKeyID)
+
+		if err := tx.Where("link_id IN (?)", trashed).Delete(&models.Click{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("link_id IN (?)", trashed).Delete(&models.LinkCheck{}).Error; err != nil {
+			return err
+		}
+
+		result := scopeOwner(tx.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", deletedBefore), ownerKeyID).
+			Delete(&models.Link{})
+		if result.Error != nil {
+			return result.Error
+		}
+		purged = result.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("erreur lors de la purge de la corbeille: %w", err)
+	}
+	return purged, nil
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/config"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/migrations"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"gorm.io/gorm"
+)
+
+// openTestDB ouvre une base SQLite en mémoire via database.Open et lui applique les migrations.
+// Une base en mémoire n'existant que pour sa connexion, le pool est limité à une connexion.
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Database.Driver = database.DriverSQLite
+	cfg.Database.DSN = ":memory:"
+	cfg.Database.MaxOpenConns = 1
+
+	db, err := database.Open(cfg)
+	if err != nil {
+		t.Fatalf("ouverture de la base: %v", err)
+	}
+	t.Cleanup(func() { database.Close(db) })
+
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		t.Fatalf("initialisation des migrations: %v", err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("application des migrations: %v", err)
+	}
+	return db
+}
+
+// createTestLink enregistre un lien et échoue le test en cas d'erreur.
+func createTestLink(t *testing.T, repo *GormLinkRepository, link models.Link) *models.Link {
+	t.Helper()
+	if link.CreatedAt.IsZero() {
+		link.CreatedAt = time.Now()
+	}
+	if err := repo.CreateLink(&link); err != nil {
+		t.Fatalf("création du lien %s: %v", link.ShortCode, err)
+	}
+	return &link
+}
+
+func TestListLinks(t *testing.T) {
+	repo := NewLinkRepository(openTestDB(t))
+	now := time.Now()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	owner := uint(7)
+
+	createTestLink(t, repo, models.Link{ShortCode: "docs", LongURL: "https://example.com/Documentation", CreatedAt: now.Add(-3 * time.Minute)})
+	createTestLink(t, repo, models.Link{ShortCode: "promo", LongURL: "https://shop.example.org/sale", CreatedAt: now.Add(-2 * time.Minute), ExpiresAt: &past})
+	createTestLink(t, repo, models.Link{ShortCode: "Blog", LongURL: "https://blog.example.net", CreatedAt: now.Add(-time.Minute), ExpiresAt: &future, OwnerKeyID: &owner})
+	createTestLink(t, repo, models.Link{ShortCode: "a_b", LongURL: "https://example.org/100%25-off", CreatedAt: now.Add(-4 * time.Minute)})
+	createTestLink(t, repo, models.Link{ShortCode: "axb", LongURL: "https://example.org/100-off", CreatedAt: now.Add(-5 * time.Minute)})
+	deleted := createTestLink(t, repo, models.Link{ShortCode: "old-docs", LongURL: "https://example.com/docs/v1"})
+	if err := repo.DeleteLink(deleted.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		filter LinkFilter
+		want   []string
+		total  int64
+	}{
+		{"tous, du plus récent au plus ancien", LinkFilter{}, []string{"Blog", "promo", "docs", "a_b", "axb"}, 5},
+		{"recherche insensible à la casse sur l'URL", LinkFilter{Query: "DOCUMENTATION"}, []string{"docs"}, 1},
+		{"recherche sur le code court", LinkFilter{Query: "blo"}, []string{"Blog"}, 1},
+		{"recherche sur le domaine", LinkFilter{Query: "example.com"}, []string{"docs"}, 1},
+		{"liens actifs", LinkFilter{Status: LinkStatusActive}, []string{"Blog", "docs", "a_b", "axb"}, 4},
+		{"liens expirés", LinkFilter{Status: LinkStatusExpired}, []string{"promo"}, 1},
+		{"liens d'une clé d'API", LinkFilter{OwnerKeyID: &owner}, []string{"Blog"}, 1},
+		{"pagination", LinkFilter{Limit: 1, Offset: 1}, []string{"promo"}, 5},
+		{"aucun résultat", LinkFilter{Query: "introuvable"}, nil, 0},
+		{"'_' recherché littéralement", LinkFilter{Query: "a_b"}, []string{"a_b"}, 1},
+		{"'%' recherché littéralement", LinkFilter{Query: "100%"}, []string{"a_b"}, 1},
+		{"caractère d'échappement recherché littéralement", LinkFilter{Query: "!"}, nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			links, total, err := repo.ListLinks(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var codes []string
+			for _, link := range links {
+				codes = append(codes, link.ShortCode)
+			}
+			if total != tt.total || len(codes) != len(tt.want) {
+				t.Fatalf("ListLinks = %v (total %d), attendu %v (total %d)", codes, total, tt.want, tt.total)
+			}
+			for i := range codes {
+				if codes[i] != tt.want[i] {
+					t.Fatalf("ListLinks = %v, attendu %v", codes, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateLinkResetsMonitorStateWhenDestinationChanges(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewLinkRepository(db)
+	link := createTestLink(t, repo, models.Link{ShortCode: "moved", LongURL: "https://old.example.com"})
+
+	up, checkedAt := true, time.Now().UTC()
+	setMonitorState := func() {
+		t.Helper()
+		err := db.Model(&models.Link{}).Where("id = ?", link.ID).
+			Updates(map[string]interface{}{"last_check_up": up, "last_checked_at": checkedAt, "content_fingerprint": "0123456789abcdef"}).Error
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	stored := func() models.Link {
+		t.Helper()
+		var stored models.Link
+		if err := db.First(&stored, link.ID).Error; err != nil {
+			t.Fatal(err)
+		}
+		return stored
+	}
+
+	// Modifier les notifications conserve l'état de la destination
+	setMonitorState()
+	link.Notify = true
+	if err := repo.UpdateLink(link); err != nil {
+		t.Fatal(err)
+	}
+	if got := stored(); got.LastCheckUp == nil || got.LastCheckedAt == nil || got.ContentFingerprint == "" || !got.Notify {
+		t.Errorf("état de la destination perdu sans changement d'URL: %+v", got)
+	}
+
+	// Changer de destination efface l'état de l'ancienne
+	link.LongURL = "https://new.example.com"
+	if err := repo.UpdateLink(link); err != nil {
+		t.Fatal(err)
+	}
+	got := stored()
+	if got.LongURL != "https://new.example.com" {
+		t.Errorf("URL '%s' non mise à jour", got.LongURL)
+	}
+	if got.LastCheckUp != nil || got.LastCheckedAt != nil || got.ContentFingerprint != "" {
+		t.Errorf("état de l'ancienne destination conservé: %+v", got)
+	}
+	if link.LastCheckUp != nil || link.ContentFingerprint != "" {
+		t.Errorf("lien renvoyé avec l'état de l'ancienne destination: %+v", link)
+	}
+}
+
+func TestRestoreLink(t *testing.T) {
+	repo := NewLinkRepository(openTestDB(t))
+	trashed := createTestLink(t, repo, models.Link{ShortCode: "trashed", LongURL: "https://example.com/trashed"})
+	active := createTestLink(t, repo, models.Link{ShortCode: "active", LongURL: "https://example.com/active"})
+	if err := repo.DeleteLink(trashed.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.RestoreLink(trashed.ID); err != nil {
+		t.Fatalf("RestoreLink: %v", err)
+	}
+	if _, err := repo.GetLinkByShortCode("trashed"); err != nil {
+		t.Errorf("lien restauré introuvable: %v", err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		id   uint
+	}{
+		{"lien déjà restauré", trashed.ID},
+		{"lien hors corbeille", active.ID},
+		{"lien inexistant", active.ID + 100},
+	} {
+		if err := repo.RestoreLink(tt.id); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("%s: RestoreLink = %v, gorm.ErrRecordNotFound attendue", tt.name, err)
+		}
+	}
+}
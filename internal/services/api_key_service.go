@@ -0,0 +1,110 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+)
+
+// apiKeyPrefix préfixe tous les secrets générés, pour les reconnaître facilement.
+const apiKeyPrefix = "usk_"
+
+// ErrInvalidAPIKey est renvoyée lorsqu'une clé d'API est absente, inconnue ou révoquée.
+var ErrInvalidAPIKey = errors.New("clé d'API invalide")
+
+// APIKeyService fournit la logique métier des clés d'API.
+type APIKeyService struct {
+	apiKeyRepo repository.APIKeyRepository
+}
+
+// NewAPIKeyService crée et retourne une nouvelle instance de APIKeyService.
+func NewAPIKeyService(apiKeyRepo repository.APIKeyRepository) *APIKeyService {
+	return &APIKeyService{
+		apiKeyRepo: apiKeyRepo,
+	}
+}
+
+// hashAPIKey calcule l'empreinte SHA-256 (hexadécimale) d'un secret.
+// Les secrets étant aléatoires et longs, un hachage rapide suffit.
+func hashAPIKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateAPIKey génère une nouvelle clé d'API.
+// Le secret en clair est renvoyé une seule fois : seule son empreinte est enregistrée.
+func (s *APIKeyService) CreateAPIKey(name string) (*models.APIKey, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", errors.New("le nom de la clé d'API est requis")
+	}
+
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, "", fmt.Errorf("erreur lors de la génération de la clé d'API: %w", err)
+	}
+	secret := apiKeyPrefix + hex.EncodeToString(raw)
+
+	key := &models.APIKey{
+		Name:      name,
+		Prefix:    secret[:len(apiKeyPrefix)+8],
+		KeyHash:   hashAPIKey(secret),
+		CreatedAt: time.Now(),
+	}
+	if err := s.apiKeyRepo.CreateAPIKey(key); err != nil {
+		return nil, "", fmt.Errorf("erreur lors de la création de la clé d'API: %w", err)
+	}
+	return key, secret, nil
+}
+
+// Authenticate vérifie un secret et renvoie la clé d'API correspondante.
+// Il renvoie ErrInvalidAPIKey si la clé est inconnue ou révoquée.
+func (s *APIKeyService) Authenticate(secret string) (*models.APIKey, error) {
+	if secret == "" {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.apiKeyRepo.GetAPIKeyByHash(hashAPIKey(secret))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, fmt.Errorf("erreur lors de la vérification de la clé d'API: %w", err)
+	}
+	if key.IsRevoked() {
+		return nil, ErrInvalidAPIKey
+	}
+
+	// La date de dernière utilisation est purement informative : une erreur ne bloque pas la requête
+	if err := s.apiKeyRepo.TouchAPIKey(key.ID, time.Now()); err != nil {
+		log.Printf("[WARN] Impossible de mettre à jour la clé d'API %d : %v", key.ID, err)
+	}
+	return key, nil
+}
+
+// ListAPIKeys récupère toutes les clés d'API.
+func (s *APIKeyService) ListAPIKeys() ([]models.APIKey, error) {
+	keys, err := s.apiKeyRepo.ListAPIKeys()
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors de la récupération des clés d'API: %w", err)
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey révoque une clé d'API : elle ne permet plus d'appeler l'API.
+func (s *APIKeyService) RevokeAPIKey(id uint) error {
+	if err := s.apiKeyRepo.RevokeAPIKey(id, time.Now()); err != nil {
+		return fmt.Errorf("erreur lors de la révocation de la clé d'API: %w", err)
+	}
+	return nil
+}
@@ -1,8 +1,12 @@
 package services
 
 import (
+	"errors"
 	"fmt"
+	"net"
+	"time"
 
+	"github.com/axellelanca/urlshortener/internal/analytics"
 	"github.com/axellelanca/urlshortener/internal/models"
 	"github.com/axellelanca/urlshortener/internal/repository" // Importe le package repository
 )
@@ -33,6 +37,26 @@ func (s *ClickService) RecordClick(click *models.Click) error {
 	return nil
 }
 
+// EraseClicksByIP supprime tous les clics d'une personne identifiée par son adresse IP (droit à l'effacement).
+// Si includeNetwork est vrai, les clics enregistrés avec l'adresse anonymisée correspondante (/24 ou /48)
+// sont aussi supprimés : cela peut inclure les clics d'autres personnes du même réseau.
+func (s *ClickService) EraseClicksByIP(ip string, includeNetwork bool) (int64, error) {
+	if net.ParseIP(ip) == nil {
+		return 0, fmt.Errorf("adresse IP invalide: %s", ip)
+	}
+
+	ips := []string{ip}
+	if includeNetwork {
+		ips = append(ips, analytics.AnonymizeIP(ip))
+	}
+
+	deleted, err := s.clickRepo.DeleteClicksByIP(ips)
+	if err != nil {
+		return 0, fmt.Errorf("erreur lors de l'effacement des clics: %w", err)
+	}
+	return deleted, nil
+}
+
 // GetClicksCountByLinkID récupère le nombre total de clics pour un LinkID donné.
 // Cette méthode pourrait être utilisée par le LinkService pour les statistiques, ou directement par l'API stats.
 func (s *ClickService) GetClicksCountByLinkID(linkID uint) (int, error) {
@@ -42,3 +66,131 @@ func (s *ClickService) GetClicksCountByLinkID(linkID uint) (int, error) {
 	}
 	return count, nil
 }
+
+// Granularités acceptées pour les séries temporelles de clics.
+const (
+	IntervalHour = "hour"
+	IntervalDay  = "day"
+	IntervalWeek = "week"
+)
+
+// maxTimeSeriesPoints limite le nombre de tranches renvoyées pour une série temporelle.
+const maxTimeSeriesPoints = 5000
+
+// ErrInvalidTimeSeries est renvoyée lorsque les paramètres d'une série temporelle sont incohérents.
+var ErrInvalidTimeSeries = errors.New("paramètres de série temporelle invalides")
+
+// TimeSeriesPoint représente le nombre de clics et de visiteurs uniques d'une tranche de temps.
+type TimeSeriesPoint struct {
+	Start          time.Time `json:"start"`
+	Clicks         int       `json:"clicks"`
+	UniqueVisitors int       `json:"unique_visitors"`
+}
+
+// bucketFunc renvoie la fonction qui associe un instant au début de sa tranche
+// dans le fuseau horaire loc, ainsi que la fonction qui passe d'une tranche à la suivante.
+// Les semaines commencent le lundi.
+func bucketFunc(interval string, loc *time.Location) (func(time.Time) time.Time, func(time.Time) time.Time, error) {
+	switch interval {
+	case IntervalHour:
+		return func(t time.Time) time.Time {
+				t = t.In(loc)
+				return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc)
+			}, func(t time.Time) time.Time {
+				return t.Add(time.Hour)
+			}, nil
+	case IntervalDay:
+		return func(t time.Time) time.Time {
+				t = t.In(loc)
+				return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+			}, func(t time.Time) time.Time {
+				return t.AddDate(0, 0, 1)
+			}, nil
+	case IntervalWeek:
+		return func(t time.Time) time.Time {
+				t = t.In(loc)
+				offset := (int(t.Weekday()) + 6) % 7 // Nombre de jours depuis lundi
+				return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, loc)
+			}, func(t time.Time) time.Time {
+				return t.AddDate(0, 0, 7)
+			}, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: intervalle '%s' inconnu (hour|day|week)", ErrInvalidTimeSeries, interval)
+	}
+}
+
+// GetClickTimeSeries renvoie le nombre de clics d'un lien par tranche de temps sur [from, to).
+// Les tranches sont calculées dans le fuseau horaire loc et les tranches sans clic valent zéro.
+// Les clics de robots ne sont comptés que si includeBots est vrai.
+func (s *ClickService) GetClickTimeSeries(linkID uint, from, to time.Time, interval string, loc *time.Location, includeBots bool) ([]TimeSeriesPoint, error) {
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: 'from' doit être antérieur à 'to'", ErrInvalidTimeSeries)
+	}
+
+	bucketOf, next, err := bucketFunc(interval, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.clickRepo.CountClicksByBucket(linkID, from, to, bucketOf, includeBots)
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors de l'agrégation des clics: %w", err)
+	}
+
+	// Génère toutes les tranches de la période pour combler les trous avec des zéros
+	var points []TimeSeriesPoint
+	for start := bucketOf(from); start.Before(to); start = bucketOf(next(start)) {
+		if len(points) >= maxTimeSeriesPoints {
+			return nil, fmt.Errorf("%w: la période demandée dépasse %d tranches", ErrInvalidTimeSeries, maxTimeSeriesPoints)
+		}
+		count := counts[start]
+		points = append(points, TimeSeriesPoint{Start: start, Clicks: count.Clicks, UniqueVisitors: count.Visitors})
+	}
+	return points, nil
+}
+
+// GetUniqueVisitors renvoie le nombre de visiteurs uniques (par jour) d'un lien.
+// Les clics de robots ne sont comptés que si includeBots est vrai.
+func (s *ClickService) GetUniqueVisitors(linkID uint, includeBots bool) (int, error) {
+	count, err := s.clickRepo.CountUniqueVisitors(linkID, includeBots)
+	if err != nil {
+		return 0, fmt.Errorf("erreur lors du comptage des visiteurs uniques: %w", err)
+	}
+	return count, nil
+}
+
+// Libellés utilisés dans les répartitions lorsqu'une dimension n'est pas renseignée.
+const (
+	DirectReferrer = "(direct)"
+	UnknownValue   = "(inconnu)"
+)
+
+// GetBreakdown répartit les clics d'un lien selon une dimension (navigateur, système, appareil...).
+// Les clics de robots ne sont comptés que si includeBots est vrai.
+func (s *ClickService) GetBreakdown(linkID uint, dimension string, limit int, includeBots bool) ([]repository.DimensionCount, error) {
+	counts, err := s.clickRepo.CountClicksByDimension(linkID, dimension, limit, includeBots)
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors de la répartition des clics: %w", err)
+	}
+	for i := range counts {
+		if counts[i].Value == "" {
+			counts[i].Value = UnknownValue
+		}
+	}
+	return counts, nil
+}
+
+// GetTopReferrers renvoie les principales pages d'origine des clics d'un lien.
+// Les clics de robots ne sont comptés que si includeBots est vrai.
+func (s *ClickService) GetTopReferrers(linkID uint, limit int, includeBots bool) ([]repository.DimensionCount, error) {
+	counts, err := s.clickRepo.CountClicksByDimension(linkID, repository.DimensionReferrer, limit, includeBots)
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors de la récupération des referrers: %w", err)
+	}
+	for i := range counts {
+		if counts[i].Value == "" {
+			counts[i].Value = DirectReferrer
+		}
+	}
+	return counts, nil
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+)
+
+// États de la destination d'un lien renvoyés par l'API de santé.
+const (
+	HealthStateUp      = "up"
+	HealthStateDown    = "down"
+	HealthStateUnknown = "unknown" // Destination pas encore vérifiée
+)
+
+// LinkHealth résume l'historique des vérifications de la destination d'un lien.
+type LinkHealth struct {
+	State         string             // Résultat de la dernière vérification
+	LastResult    string             // Classification de la dernière vérification (vide si jamais vérifiée)
+	LastCheckedAt *time.Time         // Date de la dernière vérification (nil si jamais vérifiée)
+	Window        time.Duration      // Période sur laquelle la disponibilité est calculée
+	WindowChecks  int64              // Nombre de vérifications dans la période
+	UptimePercent *float64           // Part des vérifications réussies dans la période (nil sans vérification)
+	RecentChecks  []models.LinkCheck // Vérifications les plus récentes, de la plus récente à la plus ancienne
+}
+
+// LinkHealthService fournit l'historique de disponibilité des destinations des liens.
+type LinkHealthService struct {
+	checkRepo repository.LinkCheckRepository
+}
+
+// NewLinkHealthService crée et retourne une nouvelle instance de LinkHealthService.
+func NewLinkHealthService(checkRepo repository.LinkCheckRepository) *LinkHealthService {
+	return &LinkHealthService{
+		checkRepo: checkRepo,
+	}
+}
+
+// GetLinkHealth calcule la disponibilité d'un lien sur la période 'window' précédant 'now'
+// et renvoie ses 'limit' vérifications les plus récentes.
+func (s *LinkHealthService) GetLinkHealth(link *models.Link, window time.Duration, limit int, now time.Time) (*LinkHealth, error) {
+	recent, err := s.checkRepo.GetRecentChecks(link.ID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors de la récupération de l'historique: %w", err)
+	}
+
+	total, up, err := s.checkRepo.CountChecksSince(link.ID, now.Add(-window))
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors du calcul de la disponibilité: %w", err)
+	}
+
+	health := &LinkHealth{
+		State:        HealthStateUnknown,
+		Window:       window,
+		WindowChecks: total,
+		RecentChecks: recent,
+	}
+	if len(recent) > 0 {
+		// L'historique fait foi : l'état porté par le lien peut provenir d'un cache
+		last := recent[0]
+		health.LastCheckedAt = &last.CheckedAt
+		health.LastResult = last.Result
+		health.State = HealthStateDown
+		if last.Up {
+			health.State = HealthStateUp
+		}
+	}
+	if total > 0 {
+		uptime := float64(up) * 100 / float64(total)
+		health.UptimePercent = &uptime
+	}
+	return health, nil
+}
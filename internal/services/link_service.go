@@ -6,26 +6,74 @@ import (
 	"fmt"
 	"log"
 	"math/big"
+	"regexp"
+	"strings"
 	"time"
 
 	"gorm.io/gorm" // Nécessaire pour la gestion spécifique de gorm.ErrRecordNotFound
 
 	"github.com/axellelanca/urlshortener/internal/models"
 	"github.com/axellelanca/urlshortener/internal/repository" // Importe le package repository
+	"github.com/axellelanca/urlshortener/internal/urlpolicy"
 )
 
 // Définition du jeu de caractères pour la génération des codes courts.
 const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 
+// Contraintes sur les alias personnalisés (doivent rester compatibles avec la colonne short_code).
+const (
+	minCustomCodeLength = 3
+	maxCustomCodeLength = 32
+)
+
+// maxCreateAttempts borne le nombre d'insertions tentées pour un lien à code aléatoire, lorsqu'un autre
+// lien obtient le même code entre la vérification de son unicité et l'insertion.
+const maxCreateAttempts = 3
+
+// customCodePattern définit les caractères autorisés dans un alias personnalisé.
+var customCodePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// reservedShortCodes liste les codes qui entreraient en collision avec les routes du serveur.
+var reservedShortCodes = map[string]bool{
+	"api":     true,
+	"health":  true,
+	"metrics": true,
+}
+
+// Erreurs personnalisées renvoyées lors de la création d'un lien avec un alias.
+var (
+	ErrInvalidShortCode  = errors.New("code court invalide")
+	ErrReservedShortCode = errors.New("code court réservé")
+	ErrShortCodeTaken    = errors.New("code court déjà utilisé")
+	ErrInvalidExpiration = errors.New("expiration invalide")
+)
+
+// CreateLinkOptions regroupe les paramètres optionnels de création d'un lien.
+type CreateLinkOptions struct {
+	CustomCode string     // Alias choisi par l'utilisateur (vide = code généré aléatoirement)
+	ExpiresAt  *time.Time // Date d'expiration du lien (nil = pas d'expiration)
+	OwnerKeyID *uint      // Clé d'API propriétaire du lien (nil = lien créé via la CLI)
+	Notify     bool       // Notifier les changements d'état de la destination
+}
+
+// LinkUpdate regroupe les modifications applicables à un lien existant (nil = inchangé).
+type LinkUpdate struct {
+	LongURL *string // Nouvelle URL de destination
+	Notify  *bool   // Activation des notifications du moniteur
+}
+
 // LinkService est une structure qui fournit des méthodes pour la logique métier des liens.
 type LinkService struct {
-	linkRepo repository.LinkRepository
+	linkRepo  repository.LinkRepository
+	urlPolicy *urlpolicy.Policy // Vérifie les URLs de destination (nil = aucune vérification)
 }
 
 // NewLinkService crée et retourne une nouvelle instance de LinkService.
-func NewLinkService(linkRepo repository.LinkRepository) *LinkService {
+// urlPolicy peut être nil pour les usages qui ne créent ni ne modifient de liens.
+func NewLinkService(linkRepo repository.LinkRepository, urlPolicy *urlpolicy.Policy) *LinkService {
 	return &LinkService{
-		linkRepo: linkRepo,
+		linkRepo:  linkRepo,
+		urlPolicy: urlPolicy,
 	}
 }
 
@@ -42,67 +90,344 @@ func (s *LinkService) GenerateShortCode(length int) (string, error) {
 	return string(result), nil
 }
 
+// ValidateCustomCode vérifie qu'un alias personnalisé respecte le format attendu
+// et n'entre pas en collision avec une route réservée.
+func ValidateCustomCode(code string) error {
+	if len(code) < minCustomCodeLength || len(code) > maxCustomCodeLength {
+		return fmt.Errorf("%w: la longueur doit être comprise entre %d et %d caractères",
+			ErrInvalidShortCode, minCustomCodeLength, maxCustomCodeLength)
+	}
+	if !customCodePattern.MatchString(code) {
+		return fmt.Errorf("%w: seuls les lettres, chiffres, '-' et '_' sont autorisés", ErrInvalidShortCode)
+	}
+	if reservedShortCodes[strings.ToLower(code)] {
+		return fmt.Errorf("%w: '%s'", ErrReservedShortCode, code)
+	}
+	return nil
+}
+
+// ResolveExpiration calcule la date d'expiration d'un lien à partir d'une date absolue
+// ou d'une durée de vie (TTL). Les deux options sont mutuellement exclusives.
+// Renvoie nil si aucune expiration n'est demandée.
+func ResolveExpiration(expiresAt *time.Time, ttl time.Duration, now time.Time) (*time.Time, error) {
+	if expiresAt != nil && ttl != 0 {
+		return nil, fmt.Errorf("%w: 'expires_at' et 'ttl' ne peuvent pas être utilisés ensemble", ErrInvalidExpiration)
+	}
+	if ttl < 0 {
+		return nil, fmt.Errorf("%w: le ttl doit être positif", ErrInvalidExpiration)
+	}
+	if ttl > 0 {
+		t := now.Add(ttl)
+		return &t, nil
+	}
+	if expiresAt != nil && !expiresAt.After(now) {
+		return nil, fmt.Errorf("%w: la date d'expiration doit être dans le futur", ErrInvalidExpiration)
+	}
+	return expiresAt, nil
+}
+
 // CreateLink crée un nouveau lien raccourci.
-func (s *LinkService) CreateLink(longURL string) (*models.Link, error) {
+// Si opts.CustomCode est renseigné, il est utilisé tel quel après validation ;
+// sinon un code aléatoire est généré.
+// Une URL refusée par la politique des URLs renvoie une erreur enveloppant urlpolicy.ErrRejected.
+func (s *LinkService) CreateLink(longURL string, opts CreateLinkOptions) (*models.Link, error) {
+	if err := s.checkURL(longURL); err != nil {
+		return nil, err
+	}
+
+	var shortCode string
+	if opts.CustomCode != "" {
+		code, err := s.reserveCustomCode(opts.CustomCode)
+		if err != nil {
+			return nil, err
+		}
+		shortCode = code
+	} else {
+		code, err := s.generateUniqueShortCode()
+		if err != nil {
+			return nil, err
+		}
+		shortCode = code
+	}
+
+	link := &models.Link{
+		ShortCode:  shortCode,
+		LongURL:    longURL,
+		CreatedAt:  time.Now(),
+		ExpiresAt:  opts.ExpiresAt,
+		OwnerKeyID: opts.OwnerKeyID,
+		Notify:     opts.Notify,
+	}
+
+	// Un autre lien peut prendre le même code entre la vérification et l'insertion :
+	// la contrainte d'unicité de la base tranche.
+	for attempt := 1; ; attempt++ {
+		err := s.linkRepo.CreateLink(link)
+		if err == nil {
+			return link, nil
+		}
+		if !errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, fmt.Errorf("erreur lors de la création du lien: %w", err)
+		}
+		if opts.CustomCode != "" {
+			return nil, fmt.Errorf("%w: '%s'", ErrShortCodeTaken, link.ShortCode)
+		}
+		if attempt >= maxCreateAttempts {
+			return nil, fmt.Errorf("erreur lors de la création du lien: %w", err)
+		}
+
+		// Code aléatoire : un autre code est tiré
+		log.Printf("Le code court '%s' a été attribué à un autre lien pendant la création, nouvelle tentative (%d/%d)...",
+			link.ShortCode, attempt, maxCreateAttempts)
+		code, err := s.generateUniqueShortCode()
+		if err != nil {
+			return nil, err
+		}
+		link.ID, link.ShortCode = 0, code
+	}
+}
+
+// reserveCustomCode valide un alias personnalisé et vérifie qu'il n'est pas déjà pris.
+func (s *LinkService) reserveCustomCode(code string) (string, error) {
+	if err := ValidateCustomCode(code); err != nil {
+		return "", err
+	}
+
+	inUse, err := s.isShortCodeInUse(code)
+	if err != nil {
+		return "", err
+	}
+	if inUse {
+		return "", fmt.Errorf("%w: '%s'", ErrShortCodeTaken, code)
+	}
+	return code, nil
+}
+
+// isShortCodeInUse indique si un code court est déjà attribué à un lien actif
+// ou à un lien présent dans la corbeille (qui pourrait encore être restauré).
+func (s *LinkService) isShortCodeInUse(code string) (bool, error) {
+	_, err := s.linkRepo.GetLinkByShortCode(code)
+	if err == nil {
+		return true, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, fmt.Errorf("erreur lors de la vérification du code court: %w", err)
+	}
+
+	_, err = s.linkRepo.GetTrashedLinkByShortCode(code)
+	if err == nil {
+		return true, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, fmt.Errorf("erreur lors de la vérification du code court: %w", err)
+	}
+	return false, nil
+}
+
+// generateUniqueShortCode génère un code court aléatoire en réessayant en cas de collision.
+func (s *LinkService) generateUniqueShortCode() (string, error) {
 	var shortCode string
 	maxRetries := 5
 
 	for i := 0; i < maxRetries; i++ {
 		code, err := s.GenerateShortCode(6)
 		if err != nil {
-			return nil, fmt.Errorf("erreur lors de la génération du code court: %w", err)
+			return "", fmt.Errorf("erreur lors de la génération du code court: %w", err)
 		}
 
-		_, err = s.GetLinkByShortCode(code)
+		inUse, err := s.isShortCodeInUse(code)
 		if err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				shortCode = code
-				break
-			}
-			return nil, fmt.Errorf("erreur lors de la vérification du code court: %w", err)
+			return "", err
+		}
+		if !inUse {
+			shortCode = code
+			break
 		}
 
 		log.Printf("Le code court '%s' existe déjà, nouvelle tentative (%d/%d)...", code, i+1, maxRetries)
 	}
 
 	if shortCode == "" {
-		return nil, errors.New("impossible de générer un code court unique après plusieurs tentatives")
+		return "", errors.New("impossible de générer un code court unique après plusieurs tentatives")
 	}
 
-	link := &models.Link{
-		ShortCode: shortCode,
-		LongURL:   longURL,
-		CreatedAt: time.Now(),
-	}
+	return shortCode, nil
+}
 
-	err := s.linkRepo.CreateLink(link)
+// GetLinkByShortCode récupère un lien via son code court.
+func (s *LinkService) GetLinkByShortCode(shortCode string) (*models.Link, error) {
+	link, err := s.linkRepo.GetLinkByShortCode(shortCode)
 	if err != nil {
-		return nil, fmt.Errorf("erreur lors de la création du lien: %w", err)
+		return nil, fmt.Errorf("erreur lors de la récupération du lien: %w", err)
 	}
+	return link, nil
+}
 
+// GetOwnedLink récupère un lien via son code court en vérifiant qu'il appartient à la clé d'API donnée.
+// Un lien appartenant à une autre clé est traité comme introuvable (gorm.ErrRecordNotFound),
+// afin de ne pas révéler son existence. Une clé nil donne accès à tous les liens.
+func (s *LinkService) GetOwnedLink(shortCode string, ownerKeyID *uint) (*models.Link, error) {
+	link, err := s.GetLinkByShortCode(shortCode)
+	if err != nil {
+		return nil, err
+	}
+	if !link.IsOwnedBy(ownerKeyID) {
+		return nil, fmt.Errorf("erreur lors de la récupération du lien: %w", gorm.ErrRecordNotFound)
+	}
 	return link, nil
 }
 
-// GetLinkByShortCode récupère un lien via son code court.
-func (s *LinkService) GetLinkByShortCode(shortCode string) (*models.Link, error) {
-	link, err := s.linkRepo.GetLinkByShortCode(shortCode)
+// getOwnedTrashedLink récupère un lien de la corbeille en vérifiant son propriétaire.
+func (s *LinkService) getOwnedTrashedLink(shortCode string, ownerKeyID *uint) (*models.Link, error) {
+	link, err := s.linkRepo.GetTrashedLinkByShortCode(shortCode)
 	if err != nil {
-		return nil, fmt.Errorf("erreur lors de la récupération du lien: %w", err)
+		return nil, fmt.Errorf("erreur lors de la récupération du lien supprimé: %w", err)
+	}
+	if !link.IsOwnedBy(ownerKeyID) {
+		return nil, fmt.Errorf("erreur lors de la récupération du lien supprimé: %w", gorm.ErrRecordNotFound)
 	}
 	return link, nil
 }
 
+// LinkStats regroupe les compteurs de clics d'un lien, en distinguant humains et robots.
+type LinkStats struct {
+	Link        *models.Link
+	HumanClicks int
+	BotClicks   int
+}
+
+// TotalClicks renvoie le nombre de clics à afficher : les clics de robots
+// ne sont comptés que si includeBots est vrai.
+func (st *LinkStats) TotalClicks(includeBots bool) int {
+	if includeBots {
+		return st.HumanClicks + st.BotClicks
+	}
+	return st.HumanClicks
+}
+
 // GetLinkStats récupère les statistiques pour un lien donné.
-func (s *LinkService) GetLinkStats(shortCode string) (*models.Link, int, error) {
-	link, err := s.GetLinkByShortCode(shortCode)
+func (s *LinkService) GetLinkStats(shortCode string, ownerKeyID *uint) (*LinkStats, error) {
+	link, err := s.GetOwnedLink(shortCode, ownerKeyID)
 	if err != nil {
-		return nil, 0, fmt.Errorf("erreur lors de la récupération du lien: %w", err)
+		return nil, fmt.Errorf("erreur lors de la récupération du lien: %w", err)
 	}
 
 	clickCount, err := s.linkRepo.CountClicksByLinkID(link.ID)
 	if err != nil {
-		return nil, 0, fmt.Errorf("erreur lors du comptage des clics: %w", err)
+		return nil, fmt.Errorf("erreur lors du comptage des clics: %w", err)
 	}
 
-	return link, clickCount, nil
+	botCount, err := s.linkRepo.CountBotClicksByLinkID(link.ID)
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors du comptage des clics: %w", err)
+	}
+
+	return &LinkStats{
+		Link:        link,
+		HumanClicks: clickCount - botCount,
+		BotClicks:   botCount,
+	}, nil
+}
+
+// ListLinks récupère une page de liens selon les critères fournis.
+func (s *LinkService) ListLinks(filter repository.LinkFilter) ([]models.Link, int64, error) {
+	links, total, err := s.linkRepo.ListLinks(filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("erreur lors de la récupération des liens: %w", err)
+	}
+	return links, total, nil
+}
+
+// checkURL applique la politique des URLs de destination, si elle est configurée.
+func (s *LinkService) checkURL(longURL string) error {
+	if s.urlPolicy == nil {
+		return nil
+	}
+	return s.urlPolicy.Check(longURL)
+}
+
+// UpdateLink applique des modifications à un lien existant : URL de destination et/ou notifications.
+// Une URL refusée par la politique des URLs renvoie une erreur enveloppant urlpolicy.ErrRejected.
+func (s *LinkService) UpdateLink(shortCode string, update LinkUpdate, ownerKeyID *uint) (*models.Link, error) {
+	if update.LongURL != nil {
+		if err := s.checkURL(*update.LongURL); err != nil {
+			return nil, err
+		}
+	}
+
+	link, err := s.GetOwnedLink(shortCode, ownerKeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.LongURL != nil {
+		link.LongURL = *update.LongURL
+	}
+	if update.Notify != nil {
+		link.Notify = *update.Notify
+	}
+	if err := s.linkRepo.UpdateLink(link); err != nil {
+		return nil, fmt.Errorf("erreur lors de la mise à jour du lien: %w", err)
+	}
+	return link, nil
+}
+
+// DeleteLink place un lien dans la corbeille à partir de son code court.
+// Ses clics sont conservés tant que le lien n'est pas purgé.
+func (s *LinkService) DeleteLink(shortCode string, ownerKeyID *uint) error {
+	link, err := s.GetOwnedLink(shortCode, ownerKeyID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.linkRepo.DeleteLink(link.ID); err != nil {
+		return fmt.Errorf("erreur lors de la suppression du lien: %w", err)
+	}
+	return nil
+}
+
+// ListTrashedLinks récupère les liens présents dans la corbeille.
+func (s *LinkService) ListTrashedLinks(ownerKeyID *uint) ([]models.Link, error) {
+	links, err := s.linkRepo.ListTrashedLinks(ownerKeyID)
+	if err != nil {
+		return nil, fmt.Errorf("erreur lors de la récupération de la corbeille: %w", err)
+	}
+	return links, nil
+}
+
+// RestoreLink sort un lien de la corbeille, avec l'historique de ses clics.
+func (s *LinkService) RestoreLink(shortCode string, ownerKeyID *uint) (*models.Link, error) {
+	link, err := s.getOwnedTrashedLink(shortCode, ownerKeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.linkRepo.RestoreLink(link.ID); err != nil {
+		return nil, fmt.Errorf("erreur lors de la restauration du lien: %w", err)
+	}
+	link.DeletedAt = gorm.DeletedAt{}
+	return link, nil
+}
+
+// PurgeLink supprime définitivement un lien de la corbeille ainsi que ses clics.
+func (s *LinkService) PurgeLink(shortCode string, ownerKeyID *uint) error {
+	link, err := s.getOwnedTrashedLink(shortCode, ownerKeyID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.linkRepo.PurgeLink(link.ID); err != nil {
+		return fmt.Errorf("erreur lors de la purge du lien: %w", err)
+	}
+	return nil
+}
+
+// PurgeTrash supprime définitivement les liens placés dans la corbeille depuis plus de 'olderThan'.
+// Avec olderThan à 0, toute la corbeille est vidée.
+func (s *LinkService) PurgeTrash(olderThan time.Duration, ownerKeyID *uint) (int64, error) {
+	purged, err := s.linkRepo.PurgeTrashedLinks(time.Now().Add(-olderThan), ownerKeyID)
+	if err != nil {
+		return 0, fmt.Errorf("erreur lors de la purge de la corbeille: %w", err)
+	}
+	return purged, nil
 }
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/axellelanca/urlshortener/internal/config"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/migrations"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"gorm.io/gorm"
+)
+
+// openTestLinkRepository ouvre une base SQLite en mémoire via database.Open et lui applique les migrations.
+func openTestLinkRepository(t *testing.T) *repository.GormLinkRepository {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Database.Driver = database.DriverSQLite
+	cfg.Database.DSN = ":memory:"
+	cfg.Database.MaxOpenConns = 1
+
+	db, err := database.Open(cfg)
+	if err != nil {
+		t.Fatalf("ouverture de la base: %v", err)
+	}
+	t.Cleanup(func() { database.Close(db) })
+
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		t.Fatalf("initialisation des migrations: %v", err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("application des migrations: %v", err)
+	}
+	return repository.NewLinkRepository(db)
+}
+
+// racingLinkRepository simule des liens créés par une autre requête entre la vérification
+// du code court et l'insertion : les 'conflicts' premières insertions échouent sur la contrainte d'unicité.
+type racingLinkRepository struct {
+	repository.LinkRepository
+	conflicts int
+	attempts  []string // Code court de chaque insertion tentée
+}
+
+func (r *racingLinkRepository) CreateLink(link *models.Link) error {
+	r.attempts = append(r.attempts, link.ShortCode)
+	if len(r.attempts) <= r.conflicts {
+		return gorm.ErrDuplicatedKey
+	}
+	return r.LinkRepository.CreateLink(link)
+}
+
+func TestCreateLinkRetriesRandomCodeOnConflict(t *testing.T) {
+	repo := &racingLinkRepository{LinkRepository: openTestLinkRepository(t), conflicts: 1}
+	link, err := NewLinkService(repo, nil).CreateLink("https://example.com", CreateLinkOptions{})
+	if err != nil {
+		t.Fatalf("CreateLink: %v", err)
+	}
+	if len(repo.attempts) != 2 {
+		t.Fatalf("%d insertion(s) tentée(s), 2 attendues", len(repo.attempts))
+	}
+	if link.ID == 0 || link.ShortCode != repo.attempts[1] {
+		t.Errorf("lien créé %+v, code '%s' attendu", link, repo.attempts[1])
+	}
+}
+
+func TestCreateLinkGivesUpAfterRepeatedConflicts(t *testing.T) {
+	repo := &racingLinkRepository{LinkRepository: openTestLinkRepository(t), conflicts: maxCreateAttempts}
+	_, err := NewLinkService(repo, nil).CreateLink("https://example.com", CreateLinkOptions{})
+	if err == nil || errors.Is(err, ErrShortCodeTaken) {
+		t.Errorf("CreateLink = %v, erreur interne attendue", err)
+	}
+	if len(repo.attempts) != maxCreateAttempts {
+		t.Errorf("%d insertion(s) tentée(s), %d attendues", len(repo.attempts), maxCreateAttempts)
+	}
+}
+
+func TestCreateLinkCustomCodeConflict(t *testing.T) {
+	repo := &racingLinkRepository{LinkRepository: openTestLinkRepository(t), conflicts: 1}
+	_, err := NewLinkService(repo, nil).CreateLink("https://example.com", CreateLinkOptions{CustomCode: "promo"})
+	if !errors.Is(err, ErrShortCodeTaken) {
+		t.Errorf("CreateLink = %v, ErrShortCodeTaken attendue", err)
+	}
+	if len(repo.attempts) != 1 {
+		t.Errorf("%d insertion(s) tentée(s) pour un alias, 1 attendue", len(repo.attempts))
+	}
+}
@@ -0,0 +1,268 @@
+package spool
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+const (
+	segmentExt      = ".spool"
+	headerSize      = 8
+	maxRecordSize   = 1 << 20 // Garde-fou contre une longueur d'enregistrement corrompue
+	segmentNameSize = 16
+)
+
+// ErrCorruptSegment indique qu'un segment contient un enregistrement tronqué ou corrompu.
+// Les enregistrements valides qui le précèdent restent exploitables.
+var ErrCorruptSegment = errors.New("segment du spool corrompu")
+
+// ErrClosed est renvoyée lors d'une écriture dans un spool fermé.
+var ErrClosed = errors.New("spool fermé")
+
+// Spool est un journal local en ajout seul où sont écrits les événements de clic
+// qui n'ont pas pu être placés dans le channel des workers (channel plein ou fermé).
+//
+// Il est découpé en segments numérotés (000…001.spool, 000…002.spool, ...). Seul le dernier
+// segment reçoit des écritures ; une fois scellé, un segment peut être rejoué puis supprimé.
+// Chaque enregistrement est précédé d'un en-tête de 8 octets : la longueur du contenu JSON
+// puis sa somme de contrôle CRC-32, ce qui permet de détecter un enregistrement tronqué
+// (arrêt brutal pendant l'écriture) ou corrompu.
+type Spool struct {
+	dir            string
+	maxSegmentSize int64
+
+	mu          sync.Mutex
+	current     *os.File // Segment actif, nil tant qu'aucun événement n'a été écrit
+	currentSeq  uint64
+	currentSize int64
+	nextSeq     uint64
+	closed      bool
+}
+
+// Open ouvre (ou crée) le spool situé dans le répertoire dir.
+// Les segments laissés par une exécution précédente sont considérés comme scellés
+// et seront rejoués ; les nouvelles écritures vont dans un nouveau segment.
+func Open(dir string, maxSegmentSize int64) (*Spool, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("impossible de créer le répertoire du spool '%s': %w", dir, err)
+	}
+
+	s := &Spool{dir: dir, maxSegmentSize: maxSegmentSize, nextSeq: 1}
+	seqs, err := s.segmentSeqs()
+	if err != nil {
+		return nil, err
+	}
+	if len(seqs) > 0 {
+		s.nextSeq = seqs[len(seqs)-1] + 1
+	}
+	return s, nil
+}
+
+// Append ajoute un événement à la fin du segment actif, après lui avoir attribué un identifiant unique
+// (SpoolID) qui permet de ne pas l'enregistrer deux fois si son segment est rejoué à nouveau.
+// Le segment est scellé et un nouveau est ouvert lorsque sa taille dépasse la limite configurée.
+func (s *Spool) Append(event models.ClickEvent) error {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return fmt.Errorf("impossible de générer l'identifiant de l'événement: %w", err)
+	}
+	event.SpoolID = hex.EncodeToString(id)
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("impossible d'encoder l'événement de clic: %w", err)
+	}
+
+	record := make([]byte, headerSize+len(payload))
+	binary.BigEndian.PutUint32(record[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(record[4:8], crc32.ChecksumIEEE(payload))
+	copy(record[headerSize:], payload)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrClosed
+	}
+	if s.current == nil {
+		if err := s.openSegment(); err != nil {
+			return err
+		}
+	}
+
+	// Une seule écriture par enregistrement : un arrêt brutal ne peut laisser qu'un enregistrement tronqué en fin de segment
+	n, err := s.current.Write(record)
+	s.currentSize += int64(n)
+	if err != nil {
+		return fmt.Errorf("erreur lors de l'écriture dans le spool: %w", err)
+	}
+
+	if s.currentSize >= s.maxSegmentSize {
+		return s.sealLocked()
+	}
+	return nil
+}
+
+// Seal scelle le segment actif pour qu'il puisse être rejoué. Sans effet si aucun événement n'y a été écrit.
+func (s *Spool) Seal() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sealLocked()
+}
+
+// SealedSegments renvoie les chemins des segments scellés, du plus ancien au plus récent.
+func (s *Spool) SealedSegments() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seqs, err := s.segmentSeqs()
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(seqs))
+	for _, seq := range seqs {
+		if s.current != nil && seq == s.currentSeq {
+			continue
+		}
+		paths = append(paths, s.segmentPath(seq))
+	}
+	return paths, nil
+}
+
+// Remove supprime un segment scellé après qu'il a été rejoué.
+func (s *Spool) Remove(path string) error {
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("impossible de supprimer le segment '%s': %w", path, err)
+	}
+	return nil
+}
+
+// Close scelle le segment actif et refuse les écritures suivantes.
+func (s *Spool) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	return s.sealLocked()
+}
+
+// ReadSegment lit tous les événements valides d'un segment.
+// Si un enregistrement tronqué ou corrompu est rencontré, la lecture s'arrête et les événements
+// lus jusque-là sont renvoyés avec une erreur enveloppant ErrCorruptSegment.
+func ReadSegment(path string) ([]models.ClickEvent, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("impossible d'ouvrir le segment '%s': %w", path, err)
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	var events []models.ClickEvent
+	header := make([]byte, headerSize)
+	for {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			if err == io.EOF {
+				return events, nil
+			}
+			return events, fmt.Errorf("%w: en-tête tronqué après %d événement(s)", ErrCorruptSegment, len(events))
+		}
+
+		size := binary.BigEndian.Uint32(header[0:4])
+		if size > maxRecordSize {
+			return events, fmt.Errorf("%w: taille d'enregistrement invalide (%d octets)", ErrCorruptSegment, size)
+		}
+
+		payload := make([]byte, size)
+		if _, err := io.ReadFull(reader, payload); err != nil {
+			return events, fmt.Errorf("%w: enregistrement tronqué après %d événement(s)", ErrCorruptSegment, len(events))
+		}
+		if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:8]) {
+			return events, fmt.Errorf("%w: somme de contrôle invalide après %d événement(s)", ErrCorruptSegment, len(events))
+		}
+
+		var event models.ClickEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return events, fmt.Errorf("%w: événement illisible: %v", ErrCorruptSegment, err)
+		}
+		events = append(events, event)
+	}
+}
+
+// openSegment crée le prochain segment et en fait le segment actif. Le verrou doit être détenu.
+func (s *Spool) openSegment() error {
+	seq := s.nextSeq
+	f, err := os.OpenFile(s.segmentPath(seq), os.O_CREATE|os.O_WRONLY|os.O_APPEND|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("impossible de créer un segment du spool: %w", err)
+	}
+	s.current = f
+	s.currentSeq = seq
+	s.currentSize = 0
+	s.nextSeq++
+	return nil
+}
+
+// sealLocked synchronise puis ferme le segment actif. Le verrou doit être détenu.
+func (s *Spool) sealLocked() error {
+	if s.current == nil {
+		return nil
+	}
+	f := s.current
+	s.current = nil
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("erreur lors de la synchronisation du segment: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("erreur lors de la fermeture du segment: %w", err)
+	}
+	return nil
+}
+
+// segmentSeqs liste les numéros des segments présents sur disque, triés par ordre croissant.
+func (s *Spool) segmentSeqs() ([]uint64, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("impossible de lire le répertoire du spool '%s': %w", s.dir, err)
+	}
+
+	var seqs []uint64
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExt), 10, 64)
+		if err != nil {
+			continue // Fichier étranger au spool
+		}
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	return seqs, nil
+}
+
+// segmentPath renvoie le chemin du segment portant le numéro seq.
+func (s *Spool) segmentPath(seq uint64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%0*d%s", segmentNameSize, seq, segmentExt))
+}
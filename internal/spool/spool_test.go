@@ -0,0 +1,154 @@
+package spool
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// writeSegment écrit n événements dans un spool neuf et renvoie le chemin du segment scellé.
+func writeSegment(t *testing.T, n int) string {
+	t.Helper()
+	sp, err := Open(t.TempDir(), 1<<20)
+	if err != nil {
+		t.Fatalf("ouverture du spool: %v", err)
+	}
+	for i := 0; i < n; i++ {
+		event := models.ClickEvent{LinkID: uint(i + 1), Timestamp: time.Now(), IPAddress: "203.0.113.1"}
+		if err := sp.Append(event); err != nil {
+			t.Fatalf("écriture de l'événement %d: %v", i, err)
+		}
+	}
+	if err := sp.Close(); err != nil {
+		t.Fatalf("fermeture du spool: %v", err)
+	}
+
+	segments, err := sp.SealedSegments()
+	if err != nil {
+		t.Fatalf("liste des segments: %v", err)
+	}
+	if len(segments) != 1 {
+		t.Fatalf("%d segment(s) scellé(s), 1 attendu", len(segments))
+	}
+	return segments[0]
+}
+
+func TestReadSegment(t *testing.T) {
+	events, err := ReadSegment(writeSegment(t, 3))
+	if err != nil {
+		t.Fatalf("lecture du segment: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("%d événement(s) lu(s), 3 attendus", len(events))
+	}
+
+	seen := make(map[string]bool)
+	for i, event := range events {
+		if event.LinkID != uint(i+1) {
+			t.Errorf("événement %d: LinkID %d, %d attendu", i, event.LinkID, i+1)
+		}
+		if event.SpoolID == "" || seen[event.SpoolID] {
+			t.Errorf("événement %d: SpoolID '%s' vide ou en double", i, event.SpoolID)
+		}
+		seen[event.SpoolID] = true
+	}
+}
+
+func TestReadSegmentTornTail(t *testing.T) {
+	path := writeSegment(t, 3)
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Arrêt brutal pendant l'écriture du dernier enregistrement
+	for _, cut := range []int64{1, headerSize + 1} {
+		if err := os.Truncate(path, info.Size()-cut); err != nil {
+			t.Fatal(err)
+		}
+		events, err := ReadSegment(path)
+		if !errors.Is(err, ErrCorruptSegment) {
+			t.Errorf("coupure de %d octet(s): erreur %v, ErrCorruptSegment attendue", cut, err)
+		}
+		if len(events) != 2 {
+			t.Errorf("coupure de %d octet(s): %d événement(s) récupéré(s), 2 attendus", cut, len(events))
+		}
+	}
+}
+
+func TestReadSegmentTornHeader(t *testing.T) {
+	path := writeSegment(t, 1)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte{0, 0, 0}) // En-tête incomplet
+	f.Close()
+
+	events, err := ReadSegment(path)
+	if !errors.Is(err, ErrCorruptSegment) {
+		t.Errorf("erreur %v, ErrCorruptSegment attendue", err)
+	}
+	if len(events) != 1 {
+		t.Errorf("%d événement(s) récupéré(s), 1 attendu", len(events))
+	}
+}
+
+func TestReadSegmentChecksumMismatch(t *testing.T) {
+	path := writeSegment(t, 3)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Altère un octet du contenu du deuxième enregistrement
+	second := headerSize + int(binary.BigEndian.Uint32(data[0:4]))
+	data[second+headerSize+2] ^= 0xff
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := ReadSegment(path)
+	if !errors.Is(err, ErrCorruptSegment) {
+		t.Errorf("erreur %v, ErrCorruptSegment attendue", err)
+	}
+	if len(events) != 1 {
+		t.Errorf("%d événement(s) récupéré(s), 1 attendu", len(events))
+	}
+}
+
+func TestOpenResumesAfterExistingSegments(t *testing.T) {
+	dir := t.TempDir()
+	sp, err := Open(dir, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sp.Append(models.ClickEvent{LinkID: 1})
+	sp.Close()
+
+	// Une nouvelle exécution écrit dans un nouveau segment et retrouve le précédent
+	sp, err = Open(dir, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sp.Append(models.ClickEvent{LinkID: 2})
+	sp.Close()
+
+	segments, err := sp.SealedSegments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(segments) != 2 {
+		t.Fatalf("%d segment(s), 2 attendus", len(segments))
+	}
+	for i, segment := range segments {
+		events, err := ReadSegment(segment)
+		if err != nil || len(events) != 1 || events[0].LinkID != uint(i+1) {
+			t.Errorf("segment %s: %v, %v", segment, events, err)
+		}
+	}
+}
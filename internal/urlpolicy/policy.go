@@ -0,0 +1,235 @@
+package urlpolicy
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/config"
+)
+
+// resolveTimeout borne la résolution DNS effectuée lors de la vérification d'une URL.
+const resolveTimeout = 3 * time.Second
+
+// Erreurs renvoyées par la politique. Toutes enveloppent ErrRejected.
+var (
+	ErrRejected       = errors.New("URL refusée")
+	ErrPrivateAddress = fmt.Errorf("%w: adresse privée ou locale", ErrRejected)
+)
+
+// sharedAddressSpace (100.64.0.0/10, NAT des opérateurs) n'est pas couvert par netip.Addr.IsPrivate.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// Options décrit les règles appliquées aux URLs de destination.
+type Options struct {
+	AllowedSchemes       []string // Schémas acceptés (ex: http, https)
+	AllowlistFile        string   // Fichier des seuls domaines autorisés (vide = tous)
+	BlocklistFile        string   // Fichier des domaines interdits (vide = aucun)
+	AllowPrivateNetworks bool     // Autorise les destinations privées, locales ou de bouclage
+	BaseURL              string   // URL publique du service : ses propres liens ne peuvent pas être raccourcis
+}
+
+// Policy vérifie qu'une URL peut être raccourcie puis surveillée sans danger.
+type Policy struct {
+	schemes      map[string]bool
+	allowed      []string // Domaines autorisés (vide = tous)
+	blocked      []string // Domaines interdits
+	allowPrivate bool
+	selfHost     string // Nom d'hôte du service, en minuscules
+}
+
+// New construit une politique à partir de ses options et charge les fichiers de domaines.
+func New(opts Options) (*Policy, error) {
+	p := &Policy{
+		schemes:      make(map[string]bool, len(opts.AllowedSchemes)),
+		allowPrivate: opts.AllowPrivateNetworks,
+	}
+	for _, scheme := range opts.AllowedSchemes {
+		p.schemes[strings.ToLower(strings.TrimSpace(scheme))] = true
+	}
+	if len(p.schemes) == 0 {
+		return nil, fmt.Errorf("aucun schéma d'URL autorisé")
+	}
+
+	var err error
+	if p.allowed, err = loadDomains(opts.AllowlistFile); err != nil {
+		return nil, err
+	}
+	if p.blocked, err = loadDomains(opts.BlocklistFile); err != nil {
+		return nil, err
+	}
+
+	if opts.BaseURL != "" {
+		base, err := url.Parse(opts.BaseURL)
+		if err != nil {
+			return nil, fmt.Errorf("server.base_url invalide: %w", err)
+		}
+		p.selfHost = normalizeDomain(base.Hostname())
+	}
+	return p, nil
+}
+
+// Load construit la politique décrite par la section url_policy de la configuration.
+func Load(cfg *config.Config) (*Policy, error) {
+	return New(Options{
+		AllowedSchemes:       cfg.URLPolicy.AllowedSchemes,
+		AllowlistFile:        cfg.URLPolicy.DomainAllowlistFile,
+		BlocklistFile:        cfg.URLPolicy.DomainBlocklistFile,
+		AllowPrivateNetworks: cfg.URLPolicy.AllowPrivateNetworks,
+		BaseURL:              cfg.Server.BaseURL,
+	})
+}
+
+// Check vérifie qu'une URL de destination respecte la politique : schéma autorisé, domaine
+// autorisé et non bloqué, pas de lien vers le service lui-même et, sauf si les réseaux privés
+// sont autorisés, aucune adresse privée ou locale (y compris après résolution DNS).
+func (p *Policy) Check(rawURL string) error {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return fmt.Errorf("%w: format invalide", ErrRejected)
+	}
+	if !p.schemes[strings.ToLower(u.Scheme)] {
+		return fmt.Errorf("%w: schéma '%s' non autorisé", ErrRejected, u.Scheme)
+	}
+	if u.User != nil {
+		return fmt.Errorf("%w: les identifiants dans l'URL ne sont pas autorisés", ErrRejected)
+	}
+
+	host := normalizeDomain(u.Hostname())
+	if host == "" {
+		return fmt.Errorf("%w: nom d'hôte manquant", ErrRejected)
+	}
+	if p.selfHost != "" && host == p.selfHost {
+		return fmt.Errorf("%w: une URL courte de ce service ne peut pas être raccourcie", ErrRejected)
+	}
+	if len(p.allowed) > 0 && !matchesDomain(host, p.allowed) {
+		return fmt.Errorf("%w: le domaine '%s' n'est pas autorisé", ErrRejected, host)
+	}
+	if matchesDomain(host, p.blocked) {
+		return fmt.Errorf("%w: le domaine '%s' est bloqué", ErrRejected, host)
+	}
+
+	if p.allowPrivate {
+		return nil
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if IsPrivateAddr(addr) {
+			return ErrPrivateAddress
+		}
+		return nil
+	}
+	// Formes numériques non canoniques (ex: http://2130706433/) interprétées comme des IP par les navigateurs
+	if labels := strings.Split(host, "."); isNumericLabel(labels[len(labels)-1]) {
+		return fmt.Errorf("%w: adresse IP non canonique", ErrRejected)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		// Un domaine qui ne résout pas (encore) n'est pas dangereux : il sera de nouveau vérifié à la connexion
+		return nil
+	}
+	for _, addr := range addrs {
+		if IsPrivateAddr(addr) {
+			return ErrPrivateAddress
+		}
+	}
+	return nil
+}
+
+// Dialer renvoie un net.Dialer qui refuse, au moment de la connexion, les adresses privées ou
+// locales. Le contrôle porte sur l'adresse réellement contactée : il résiste donc au DNS rebinding
+// et aux redirections vers une adresse interne.
+func (p *Policy) Dialer(timeout time.Duration) *net.Dialer {
+	dialer := &net.Dialer{Timeout: timeout}
+	if p.allowPrivate {
+		return dialer
+	}
+	dialer.Control = func(network, address string, _ syscall.RawConn) error {
+		addrPort, err := netip.ParseAddrPort(address)
+		if err != nil {
+			return fmt.Errorf("%w: adresse '%s' non reconnue", ErrRejected, address)
+		}
+		if IsPrivateAddr(addrPort.Addr()) {
+			return fmt.Errorf("%w (%s)", ErrPrivateAddress, addrPort.Addr())
+		}
+		return nil
+	}
+	return dialer
+}
+
+// IsPrivateAddr indique si une adresse IP n'est pas routable publiquement : bouclage, réseaux privés,
+// lien local (dont les métadonnées cloud 169.254.169.254), NAT des opérateurs, multicast ou non spécifiée.
+func IsPrivateAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() ||
+		addr.IsUnspecified() || sharedAddressSpace.Contains(addr) ||
+		(addr.Is4() && addr.As4()[0] == 0) // 0.0.0.0/8 : « ce réseau »
+}
+
+// loadDomains lit un fichier de domaines : un domaine par ligne, lignes vides et commentaires (#) ignorés.
+func loadDomains(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("impossible d'ouvrir la liste de domaines '%s': %w", path, err)
+	}
+	defer file.Close()
+
+	var domains []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if domain := normalizeDomain(strings.TrimPrefix(strings.TrimSpace(line), "*.")); domain != "" {
+			domains = append(domains, domain)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("impossible de lire la liste de domaines '%s': %w", path, err)
+	}
+	return domains, nil
+}
+
+// matchesDomain indique si host est l'un des domaines ou l'un de leurs sous-domaines.
+func matchesDomain(host string, domains []string) bool {
+	for _, domain := range domains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeDomain met un nom d'hôte en minuscules et retire le point final éventuel.
+func normalizeDomain(host string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
+
+// isNumericLabel indique si un label DNS est un nombre décimal, octal ou hexadécimal (0x...).
+func isNumericLabel(label string) bool {
+	digits, base := label, "0123456789"
+	if hex, ok := strings.CutPrefix(label, "0x"); ok {
+		digits, base = hex, "0123456789abcdef"
+	}
+	if digits == "" {
+		return false
+	}
+	for _, r := range digits {
+		if !strings.ContainsRune(base, r) {
+			return false
+		}
+	}
+	return true
+}
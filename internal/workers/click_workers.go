@@ -2,44 +2,179 @@ package workers
 
 import (
 	"log"
+	"sync"
+	"time"
+	"unicode/utf8"
 
+	"github.com/axellelanca/urlshortener/internal/analytics"
+	"github.com/axellelanca/urlshortener/internal/metrics"
 	"github.com/axellelanca/urlshortener/internal/models"
 	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/spool"
 )
 
+// ClickWorkerOptions regroupe les paramètres de traitement des événements de clic.
+type ClickWorkerOptions struct {
+	WorkerCount   int                      // Nombre de goroutines qui traitent les clics
+	BatchSize     int                      // Nombre maximum de clics accumulés avant écriture en base
+	FlushInterval time.Duration            // Délai maximum avant l'écriture d'un lot incomplet
+	Hasher        *analytics.VisitorHasher // Calcule l'empreinte quotidienne de chaque visiteur (visiteurs uniques)
+	AnonymizeIP   bool                     // Tronque les adresses IP avant de les enregistrer (mode RGPD)
+}
+
 // StartClickWorkers lance un nombre spécifié de workers pour traiter les événements de clic.
-// Chaque worker écoute le channel d'événements et enregistre les clics en base de données.
-func StartClickWorkers(clickEvents chan models.ClickEvent, clickRepo repository.ClickRepository, workerCount int) {
-	log.Printf("[WORKERS] Démarrage de %d workers pour le traitement des clics...", workerCount)
+// Chaque worker accumule les clics reçus sur le channel et les enregistre en base par lots,
+// dès que le lot atteint BatchSize clics ou que FlushInterval s'est écoulé.
+// Les workers s'arrêtent quand le channel est fermé, après avoir enregistré les clics restants :
+// le WaitGroup renvoyé permet d'attendre la fin de ce drainage.
+func StartClickWorkers(clickEvents chan models.ClickEvent, clickRepo repository.ClickRepository, opts ClickWorkerOptions) *sync.WaitGroup {
+	if opts.BatchSize < 1 {
+		opts.BatchSize = 1
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = time.Second
+	}
 
-	for i := 0; i < workerCount; i++ {
+	log.Printf("[WORKERS] Démarrage de %d workers pour le traitement des clics (lots de %d, délai max %v)...",
+		opts.WorkerCount, opts.BatchSize, opts.FlushInterval)
+
+	var wg sync.WaitGroup
+	for i := 0; i < opts.WorkerCount; i++ {
+		wg.Add(1)
 		go func(workerID int) {
-			log.Printf("[WORKERS] Worker %d démarré et en attente d'événements", workerID)
-			for event := range clickEvents {
-				processClickEvent(event, clickRepo, workerID)
-			}
+			defer wg.Done()
+			runClickWorker(clickEvents, clickRepo, opts, workerID)
 		}(i)
 	}
+	return &wg
 }
 
-// processClickEvent traite un événement de clic individuel.
-func processClickEvent(event models.ClickEvent, clickRepo repository.ClickRepository, workerID int) {
-	log.Printf("[WORKERS] Worker %d : Traitement d'un clic pour le lien ID %d (IP: %s, UA: %s)",
-		workerID, event.LinkID, event.IPAddress, event.UserAgent)
+// SpoolPendingClicks place dans le spool les événements restés dans le channel des clics, qui doit être fermé.
+// Elle sert à l'arrêt du serveur lorsque les workers n'ont pas tout enregistré dans le délai imparti :
+// les événements sont alors rejoués au prochain démarrage au lieu d'être perdus.
+// Renvoie le nombre d'événements placés dans le spool et le nombre d'événements perdus (spool absent ou en échec).
+func SpoolPendingClicks(clickEvents chan models.ClickEvent, clickSpool *spool.Spool) (spooled, lost int) {
+	for event := range clickEvents {
+		if clickSpool == nil {
+			lost++
+			continue
+		}
+		if err := clickSpool.Append(event); err != nil {
+			log.Printf("[WORKERS] ERREUR lors de l'écriture d'un clic en attente dans le spool pour le lien ID %d : %v", event.LinkID, err)
+			lost++
+			continue
+		}
+		spooled++
+	}
+	metrics.ClickEventsSpooled.Add(float64(spooled))
+	metrics.ClickEventsDropped.Add(float64(lost))
+	return spooled, lost
+}
 
-	click := models.Click{
-		LinkID:    event.LinkID,
-		Timestamp: event.Timestamp,
-		UserAgent: event.UserAgent,
-		IPAddress: event.IPAddress,
+// runClickWorker est la boucle d'un worker : elle accumule les clics et déclenche l'écriture des lots.
+func runClickWorker(clickEvents chan models.ClickEvent, clickRepo repository.ClickRepository, opts ClickWorkerOptions, workerID int) {
+	log.Printf("[WORKERS] Worker %d démarré et en attente d'événements", workerID)
+
+	batch := make([]models.Click, 0, opts.BatchSize)
+	ticker := time.NewTicker(opts.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event, ok := <-clickEvents:
+			if !ok {
+				// Channel fermé : on enregistre ce qui reste avant de s'arrêter
+				batch = flushClicks(batch, clickRepo, workerID)
+				log.Printf("[WORKERS] Worker %d arrêté", workerID)
+				return
+			}
+			batch = append(batch, buildClick(event, opts))
+			if len(batch) >= opts.BatchSize {
+				batch = flushClicks(batch, clickRepo, workerID)
+			}
+		case <-ticker.C:
+			batch = flushClicks(batch, clickRepo, workerID)
+		}
 	}
+}
 
-	if err := clickRepo.CreateClick(&click); err != nil {
-		log.Printf("[WORKERS] Worker %d : ERREUR lors de l'enregistrement du clic pour le lien ID %d : %v",
-			workerID, event.LinkID, err)
-		return
+// buildClick transforme un événement de clic brut en modèle Click prêt à être enregistré.
+func buildClick(event models.ClickEvent, opts ClickWorkerOptions) models.Click {
+	// En mode RGPD, l'empreinte est calculée sur l'IP déjà tronquée : le sel quotidien se déduisant du secret
+	// configuré, une empreinte de l'IP complète permettrait de la retrouver en essayant toutes les adresses IPv4.
+	// Les visiteurs d'un même réseau avec le même User-Agent sont alors comptés une seule fois.
+	ipAddress := event.IPAddress
+	if opts.AnonymizeIP {
+		ipAddress = analytics.AnonymizeIP(ipAddress)
 	}
+	visitorHash := opts.Hasher.Hash(ipAddress, event.UserAgent, event.Timestamp)
+
+	ua := analytics.ParseUserAgent(event.UserAgent)
 
-	log.Printf("[WORKERS] Worker %d : Clic enregistré avec succès pour le lien ID %d (Click ID: %d)",
-		workerID, event.LinkID, click.ID)
+	var spoolID *string
+	if event.SpoolID != "" {
+		spoolID = &event.SpoolID
+	}
+
+	return models.Click{
+		LinkID:         event.LinkID,
+		Timestamp:      event.Timestamp.UTC(), // Stockage en UTC pour des comparaisons cohérentes en base
+		UserAgent:      truncate(event.UserAgent, 255),
+		IPAddress:      ipAddress,
+		Referrer:       event.Referrer,
+		Browser:        ua.Browser,
+		BrowserVersion: ua.BrowserVersion,
+		OS:             ua.OS,
+		DeviceType:     ua.Device,
+		IsBot:          event.IsBot || ua.Device == analytics.DeviceBot,
+		VisitorHash:    visitorHash,
+		SpoolID:        spoolID,
+	}
+}
+
+// flushClicks enregistre un lot de clics en une seule requête et renvoie le lot vidé, prêt à être réutilisé.
+// Si l'insertion groupée échoue, les clics sont réessayés un par un afin de ne perdre que les clics fautifs.
+func flushClicks(batch []models.Click, clickRepo repository.ClickRepository, workerID int) []models.Click {
+	if len(batch) == 0 {
+		return batch
+	}
+
+	start := time.Now()
+	err := clickRepo.CreateClicks(batch)
+	metrics.ClickInsertDuration.Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		metrics.ClickInsertErrors.Inc()
+		log.Printf("[WORKERS] Worker %d : ERREUR lors de l'enregistrement d'un lot de %d clics, nouvel essai clic par clic : %v",
+			workerID, len(batch), err)
+		for i := range batch {
+			// L'insertion groupée annulée a pu attribuer un ID au clic : il doit être réinséré sans
+			batch[i].ID = 0
+			if err := clickRepo.CreateClick(&batch[i]); err != nil {
+				metrics.ClickInsertErrors.Inc()
+				log.Printf("[WORKERS] Worker %d : ERREUR lors de l'enregistrement du clic pour le lien ID %d : %v",
+					workerID, batch[i].LinkID, err)
+				continue
+			}
+			metrics.ClicksInserted.Inc()
+		}
+	} else {
+		metrics.ClicksInserted.Add(float64(len(batch)))
+		log.Printf("[WORKERS] Worker %d : %d clic(s) enregistré(s)", workerID, len(batch))
+	}
+
+	return batch[:0]
+}
+
+// truncate coupe une chaîne à maxLen octets pour respecter la taille des colonnes,
+// sans couper un caractère UTF-8 en deux.
+func truncate(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	s = s[:maxLen]
+	for len(s) > 0 && !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s
 }
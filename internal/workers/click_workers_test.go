@@ -0,0 +1,199 @@
+package workers
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/analytics"
+	"github.com/axellelanca/urlshortener/internal/config"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/migrations"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/spool"
+)
+
+// benchClickCount est le nombre de clics enregistrés à chaque itération du benchmark,
+// soit la taille de lot par défaut des workers.
+const benchClickCount = 100
+
+// openTestRepository crée une base SQLite temporaire migrée, avec un lien (ID 1), et renvoie son dépôt de clics.
+func openTestRepository(tb testing.TB) *repository.GormClickRepository {
+	tb.Helper()
+	cfg := &config.Config{}
+	cfg.Database.Driver = database.DriverSQLite
+	cfg.Database.Name = filepath.Join(tb.TempDir(), "bench.db")
+
+	db, err := database.Open(cfg)
+	if err != nil {
+		tb.Fatalf("ouverture de la base: %v", err)
+	}
+	tb.Cleanup(func() { database.Close(db) })
+
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		tb.Fatalf("initialisation des migrations: %v", err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		tb.Fatalf("application des migrations: %v", err)
+	}
+	if err := db.Create(&models.Link{ShortCode: "bench", LongURL: "https://example.com", CreatedAt: time.Now()}).Error; err != nil {
+		tb.Fatalf("création du lien: %v", err)
+	}
+	return repository.NewClickRepository(db)
+}
+
+// newTestHasher crée le calculateur d'empreintes de visiteurs utilisé par les tests.
+func newTestHasher(tb testing.TB) *analytics.VisitorHasher {
+	tb.Helper()
+	hasher, err := analytics.NewVisitorHasher("test")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return hasher
+}
+
+// benchClicks construit des clics tels que les workers les enregistrent.
+func benchClicks(tb testing.TB, n int) []models.Click {
+	opts := ClickWorkerOptions{Hasher: newTestHasher(tb)}
+	clicks := make([]models.Click, n)
+	for i := range clicks {
+		clicks[i] = buildClick(models.ClickEvent{
+			LinkID:    1,
+			Timestamp: time.Now(),
+			UserAgent: "Mozilla/5.0 (X11; Linux x86_64; rv:120.0) Gecko/20100101 Firefox/120.0",
+			IPAddress: fmt.Sprintf("203.0.113.%d", i%256),
+			Referrer:  "example.org",
+		}, opts)
+	}
+	return clicks
+}
+
+// BenchmarkClickWorkers compare l'enregistrement des clics un par un et par lots (CreateClicks).
+func BenchmarkClickWorkers(b *testing.B) {
+	b.Run("single", func(b *testing.B) {
+		repo := openTestRepository(b)
+		clicks := benchClicks(b, benchClickCount)
+		b.ResetTimer()
+		for n := 0; n < b.N; n++ {
+			for i := range clicks {
+				clicks[i].ID = 0
+				if err := repo.CreateClick(&clicks[i]); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	b.Run("batch", func(b *testing.B) {
+		repo := openTestRepository(b)
+		clicks := benchClicks(b, benchClickCount)
+		b.ResetTimer()
+		for n := 0; n < b.N; n++ {
+			for i := range clicks {
+				clicks[i].ID = 0
+			}
+			if err := repo.CreateClicks(clicks); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// failingBatchRepository simule une insertion groupée qui attribue des IDs avant d'échouer.
+type failingBatchRepository struct {
+	repository.ClickRepository
+	insertedIDs []uint // ID de chaque clic au moment de son insertion individuelle
+}
+
+func (r *failingBatchRepository) CreateClicks(clicks []models.Click) error {
+	for i := range clicks {
+		clicks[i].ID = uint(i + 1)
+	}
+	return errors.New("échec simulé")
+}
+
+func (r *failingBatchRepository) CreateClick(click *models.Click) error {
+	r.insertedIDs = append(r.insertedIDs, click.ID)
+	return nil
+}
+
+func TestFlushClicksRetriesWithoutBatchIDs(t *testing.T) {
+	repo := &failingBatchRepository{}
+	batch := flushClicks(benchClicks(t, 3), repo, 0)
+
+	if len(batch) != 0 {
+		t.Errorf("lot non vidé: %d clic(s)", len(batch))
+	}
+	if len(repo.insertedIDs) != 3 {
+		t.Fatalf("%d clic(s) réinséré(s), 3 attendus", len(repo.insertedIDs))
+	}
+	for i, id := range repo.insertedIDs {
+		if id != 0 {
+			t.Errorf("clic %d réinséré avec l'ID %d de l'insertion groupée", i, id)
+		}
+	}
+}
+
+func TestBuildClickHashesAnonymizedIP(t *testing.T) {
+	hasher := newTestHasher(t)
+	at := time.Date(2026, time.October, 14, 9, 0, 0, 0, time.UTC)
+	userAgent := "Mozilla/5.0 (X11; Linux x86_64; rv:120.0) Gecko/20100101 Firefox/120.0"
+	event := func(ip string) models.ClickEvent {
+		return models.ClickEvent{LinkID: 1, Timestamp: at, UserAgent: userAgent, IPAddress: ip}
+	}
+
+	private := ClickWorkerOptions{Hasher: hasher, AnonymizeIP: true}
+	click := buildClick(event("203.0.113.42"), private)
+	if click.IPAddress != "203.0.113.0" {
+		t.Errorf("IP enregistrée '%s', 203.0.113.0 attendue", click.IPAddress)
+	}
+	// L'empreinte ne doit dépendre que de l'IP tronquée, sans quoi elle permettrait de retrouver l'IP complète
+	if want := hasher.Hash("203.0.113.0", userAgent, at); click.VisitorHash != want {
+		t.Errorf("empreinte '%s' calculée sur l'IP complète, '%s' attendue", click.VisitorHash, want)
+	}
+	if other := buildClick(event("203.0.113.7"), private); other.VisitorHash != click.VisitorHash {
+		t.Error("empreintes différentes pour deux IP du même /24 en mode RGPD")
+	}
+
+	if full := buildClick(event("203.0.113.42"), ClickWorkerOptions{Hasher: hasher}); full.VisitorHash != hasher.Hash("203.0.113.42", userAgent, at) {
+		t.Error("empreinte hors mode RGPD non calculée sur l'IP complète")
+	}
+}
+
+func TestSpoolPendingClicks(t *testing.T) {
+	pending := func() chan models.ClickEvent {
+		clickEvents := make(chan models.ClickEvent, 3)
+		for i := 1; i <= 3; i++ {
+			clickEvents <- models.ClickEvent{LinkID: uint(i), Timestamp: time.Now()}
+		}
+		close(clickEvents)
+		return clickEvents
+	}
+
+	clickSpool, err := spool.Open(t.TempDir(), 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spooled, lost := SpoolPendingClicks(pending(), clickSpool); spooled != 3 || lost != 0 {
+		t.Errorf("%d événement(s) placé(s) dans le spool, %d perdu(s) ; attendu 3 et 0", spooled, lost)
+	}
+	if err := clickSpool.Close(); err != nil {
+		t.Fatal(err)
+	}
+	segments, err := clickSpool.SealedSegments()
+	if err != nil || len(segments) != 1 {
+		t.Fatalf("%d segment(s), 1 attendu (%v)", len(segments), err)
+	}
+	if events, err := spool.ReadSegment(segments[0]); err != nil || len(events) != 3 {
+		t.Errorf("%d événement(s) relu(s) dans le spool, 3 attendus (%v)", len(events), err)
+	}
+
+	// Sans spool, les événements restants sont comptés comme perdus
+	if spooled, lost := SpoolPendingClicks(pending(), nil); spooled != 0 || lost != 3 {
+		t.Errorf("%d événement(s) placé(s) dans le spool, %d perdu(s) sans spool ; attendu 0 et 3", spooled, lost)
+	}
+}
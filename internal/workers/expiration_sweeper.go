@@ -0,0 +1,70 @@
+package workers
+
+import (
+	"log"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/repository"
+)
+
+// ExpirationSweeper déplace périodiquement les liens expirés vers la corbeille.
+// Un lien expiré reste actif pendant le délai de grâce (il renvoie alors 410 Gone),
+// puis il est placé dans la corbeille avec ses clics, d'où il peut être restauré ou purgé.
+type ExpirationSweeper struct {
+	linkRepo    repository.LinkRepository // Pour mettre les liens expirés à la corbeille
+	interval    time.Duration             // Intervalle entre chaque passage
+	gracePeriod time.Duration             // Délai de conservation après expiration
+	stop        chan struct{}             // Fermé par Stop pour arrêter la boucle
+	done        chan struct{}             // Fermé quand la boucle est terminée
+}
+
+// NewExpirationSweeper crée et retourne une nouvelle instance de ExpirationSweeper.
+func NewExpirationSweeper(linkRepo repository.LinkRepository, interval, gracePeriod time.Duration) *ExpirationSweeper {
+	return &ExpirationSweeper{
+		linkRepo:    linkRepo,
+		interval:    interval,
+		gracePeriod: gracePeriod,
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// Start lance la boucle de purge périodique.
+// Cette fonction est conçue pour être lancée dans une goroutine séparée.
+func (s *ExpirationSweeper) Start() {
+	log.Printf("[SWEEPER] Démarrage du nettoyage des liens expirés (intervalle %v, délai de grâce %v)...",
+		s.interval, s.gracePeriod)
+	defer close(s.done)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.sweep()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.sweep()
+		case <-s.stop:
+			log.Println("[SWEEPER] Arrêté.")
+			return
+		}
+	}
+}
+
+// Stop arrête la boucle périodique et attend la fin du passage en cours.
+func (s *ExpirationSweeper) Stop() {
+	close(s.stop)
+	<-s.done
+}
+
+// sweep place dans la corbeille les liens expirés depuis plus longtemps que le délai de grâce.
+func (s *ExpirationSweeper) sweep() {
+	deleted, err := s.linkRepo.DeleteExpiredLinks(time.Now().Add(-s.gracePeriod))
+	if err != nil {
+		log.Printf("[SWEEPER] ERREUR lors du nettoyage des liens expirés : %v", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("[SWEEPER] %d lien(s) expiré(s) déplacé(s) dans la corbeille.", deleted)
+	}
+}
@@ -0,0 +1,86 @@
+package workers
+
+import (
+	"log"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/repository"
+)
+
+// Modes de rétention des clics.
+const (
+	RetentionModeDelete    = "delete"    // Les anciens clics sont supprimés
+	RetentionModeAnonymize = "anonymize" // Les anciens clics perdent leurs données personnelles mais restent comptés
+)
+
+// RetentionJob applique périodiquement la politique de rétention des clics :
+// les clics plus anciens que la durée de rétention sont supprimés ou anonymisés.
+type RetentionJob struct {
+	clickRepo repository.ClickRepository // Pour supprimer ou anonymiser les anciens clics
+	interval  time.Duration              // Intervalle entre chaque passage
+	retention time.Duration              // Durée de conservation des clics
+	mode      string                     // RetentionModeDelete ou RetentionModeAnonymize
+	stop      chan struct{}              // Fermé par Stop pour arrêter la boucle
+	done      chan struct{}              // Fermé quand la boucle est terminée
+}
+
+// NewRetentionJob crée et retourne une nouvelle instance de RetentionJob.
+func NewRetentionJob(clickRepo repository.ClickRepository, interval, retention time.Duration, mode string) *RetentionJob {
+	return &RetentionJob{
+		clickRepo: clickRepo,
+		interval:  interval,
+		retention: retention,
+		mode:      mode,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// Start lance la boucle d'application de la politique de rétention.
+// Cette fonction est conçue pour être lancée dans une goroutine séparée.
+func (j *RetentionJob) Start() {
+	log.Printf("[RETENTION] Démarrage de la rétention des clics (mode %s, conservation %v, intervalle %v)...",
+		j.mode, j.retention, j.interval)
+	defer close(j.done)
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	j.apply()
+
+	for {
+		select {
+		case <-ticker.C:
+			j.apply()
+		case <-j.stop:
+			log.Println("[RETENTION] Arrêté.")
+			return
+		}
+	}
+}
+
+// Stop arrête la boucle périodique et attend la fin du passage en cours.
+func (j *RetentionJob) Stop() {
+	close(j.stop)
+	<-j.done
+}
+
+// apply supprime ou anonymise les clics plus anciens que la durée de rétention.
+func (j *RetentionJob) apply() {
+	before := time.Now().Add(-j.retention)
+
+	var affected int64
+	var err error
+	if j.mode == RetentionModeAnonymize {
+		affected, err = j.clickRepo.AnonymizeClicksBefore(before)
+	} else {
+		affected, err = j.clickRepo.DeleteClicksBefore(before)
+	}
+	if err != nil {
+		log.Printf("[RETENTION] ERREUR lors de l'application de la rétention : %v", err)
+		return
+	}
+	if affected > 0 {
+		log.Printf("[RETENTION] %d clic(s) antérieur(s) au %s traité(s) (mode %s).",
+			affected, before.Format(time.RFC3339), j.mode)
+	}
+}
@@ -0,0 +1,133 @@
+package workers
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/metrics"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/spool"
+)
+
+// SpoolReplayer réinjecte périodiquement en base les événements de clic écrits dans le spool
+// lorsque le channel des workers était plein. Il n'intervient que lorsque la pression est
+// retombée (channel rempli à moins de moitié), et rejoue aussi au démarrage les segments
+// laissés par une exécution précédente (arrêt brutal ou clics reçus pendant l'arrêt).
+type SpoolReplayer struct {
+	spool       *spool.Spool               // Segments d'événements en attente
+	clickEvents chan models.ClickEvent     // Channel des workers, pour mesurer la pression
+	clickRepo   repository.ClickRepository // Pour enregistrer les clics rejoués
+	opts        ClickWorkerOptions         // Mêmes traitements que les workers (empreinte, anonymisation)
+	interval    time.Duration              // Intervalle entre chaque tentative de rejeu
+	stop        chan struct{}              // Fermé par Stop pour arrêter la boucle
+	done        chan struct{}              // Fermé quand la boucle est terminée
+}
+
+// NewSpoolReplayer crée et retourne une nouvelle instance de SpoolReplayer.
+func NewSpoolReplayer(sp *spool.Spool, clickEvents chan models.ClickEvent, clickRepo repository.ClickRepository, opts ClickWorkerOptions, interval time.Duration) *SpoolReplayer {
+	return &SpoolReplayer{
+		spool:       sp,
+		clickEvents: clickEvents,
+		clickRepo:   clickRepo,
+		opts:        opts,
+		interval:    interval,
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// Start lance la boucle de rejeu périodique.
+// Cette fonction est conçue pour être lancée dans une goroutine séparée.
+func (r *SpoolReplayer) Start() {
+	log.Printf("[SPOOL] Démarrage du rejeu des clics en attente (intervalle %v)...", r.interval)
+	defer close(r.done)
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	r.replay()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.replay()
+		case <-r.stop:
+			log.Println("[SPOOL] Arrêté.")
+			return
+		}
+	}
+}
+
+// Stop arrête la boucle périodique et attend la fin du rejeu en cours.
+func (r *SpoolReplayer) Stop() {
+	close(r.stop)
+	<-r.done
+}
+
+// underPressure indique si le channel des workers est encore trop rempli pour rejouer le spool.
+func (r *SpoolReplayer) underPressure() bool {
+	return len(r.clickEvents) > cap(r.clickEvents)/2
+}
+
+// replay enregistre en base le contenu des segments scellés, du plus ancien au plus récent.
+// Chaque segment est enregistré en une seule transaction puis supprimé : en cas d'échec,
+// il est conservé et retenté au passage suivant. Les événements portent un identifiant unique :
+// un segment déjà enregistré mais pas encore supprimé (arrêt brutal) ne crée pas de doublons.
+func (r *SpoolReplayer) replay() {
+	if r.underPressure() {
+		return
+	}
+
+	// Sceller le segment actif pour rejouer aussi les événements les plus récents
+	if err := r.spool.Seal(); err != nil {
+		log.Printf("[SPOOL] ERREUR lors du scellement du segment actif : %v", err)
+	}
+
+	segments, err := r.spool.SealedSegments()
+	if err != nil {
+		log.Printf("[SPOOL] ERREUR lors de la lecture du spool : %v", err)
+		return
+	}
+
+	for _, segment := range segments {
+		if r.underPressure() {
+			log.Println("[SPOOL] Channel de clics de nouveau chargé, rejeu reporté.")
+			return
+		}
+
+		events, err := spool.ReadSegment(segment)
+		if err != nil && !errors.Is(err, spool.ErrCorruptSegment) {
+			log.Printf("[SPOOL] ERREUR : %v", err)
+			return
+		}
+		if err != nil {
+			// Les événements valides précédant l'enregistrement fautif sont tout de même rejoués
+			log.Printf("[SPOOL] Segment %s partiellement illisible, %d événement(s) récupéré(s) : %v", segment, len(events), err)
+		}
+
+		clicks := make([]models.Click, 0, len(events))
+		for _, event := range events {
+			clicks = append(clicks, buildClick(event, r.opts))
+		}
+		start := time.Now()
+		inserted, err := r.clickRepo.CreateSpooledClicks(clicks)
+		metrics.ClickInsertDuration.Observe(time.Since(start).Seconds())
+		if err != nil {
+			metrics.ClickInsertErrors.Inc()
+			log.Printf("[SPOOL] ERREUR lors de l'enregistrement des clics du segment %s, nouvel essai plus tard : %v", segment, err)
+			return
+		}
+
+		metrics.ClicksInserted.Add(float64(inserted))
+		if skipped := int64(len(clicks)) - inserted; skipped > 0 {
+			log.Printf("[SPOOL] %d clic(s) du segment %s déjà enregistré(s), ignoré(s)", skipped, segment)
+		}
+
+		if err := r.spool.Remove(segment); err != nil {
+			log.Printf("[SPOOL] ERREUR : %v", err)
+			return
+		}
+		log.Printf("[SPOOL] %d clic(s) en attente enregistré(s) depuis %s", inserted, segment)
+	}
+}
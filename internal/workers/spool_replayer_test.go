@@ -0,0 +1,55 @@
+package workers
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/spool"
+)
+
+func TestSpoolReplayIsIdempotent(t *testing.T) {
+	repo := openTestRepository(t)
+	sp, err := spool.Open(t.TempDir(), 1<<20)
+	if err != nil {
+		t.Fatalf("ouverture du spool: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := sp.Append(models.ClickEvent{LinkID: 1, Timestamp: time.Now(), IPAddress: "203.0.113.1"}); err != nil {
+			t.Fatalf("écriture dans le spool: %v", err)
+		}
+	}
+	if err := sp.Seal(); err != nil {
+		t.Fatal(err)
+	}
+	segments, err := sp.SealedSegments()
+	if err != nil || len(segments) != 1 {
+		t.Fatalf("segments: %v, %v", segments, err)
+	}
+	content, err := os.ReadFile(segments[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replayer := NewSpoolReplayer(sp, make(chan models.ClickEvent, 10), repo,
+		ClickWorkerOptions{Hasher: newTestHasher(t)}, time.Minute)
+	replayer.replay()
+
+	// Arrêt brutal entre l'enregistrement et la suppression : le segment réapparaît et est rejoué
+	if err := os.WriteFile(segments[0], content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	replayer.replay()
+
+	if _, err := os.Stat(segments[0]); !os.IsNotExist(err) {
+		t.Errorf("segment non supprimé après le rejeu: %v", err)
+	}
+	count, err := repo.CountClicksByLinkID(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Errorf("%d clic(s) enregistré(s), 3 attendus", count)
+	}
+}